@@ -0,0 +1,222 @@
+package battle
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// ActionType identifies a move a player can make
+type ActionType string
+
+const (
+	ActionDraw        ActionType = "draw"
+	ActionPlayCard    ActionType = "play_card"
+	ActionAttack      ActionType = "attack"
+	ActionChangePhase ActionType = "change_phase"
+	ActionEndTurn     ActionType = "end_turn"
+	ActionConcede     ActionType = "concede"
+	ActionRespond     ActionType = "respond"
+	ActionPass        ActionType = "pass"
+	ActionMulligan    ActionType = "mulligan"
+	// ActionTimeout is recorded by the engine when a player's clock runs
+	// out; players cannot submit it
+	ActionTimeout ActionType = "timeout"
+)
+
+// Action is a single move submitted by a player. Cards are addressed by
+// instance ID so a move stays unambiguous however the hand and field shift
+// around it. Only the fields used by its Type are meaningful. TargetID is
+// the defending card of an attack, empty for a direct attack, or the
+// creature an equipment card is played onto. CardID of a response is an
+// instant in hand or a face-down trap. CardIDs of a mulligan are the
+// cards shuffled back, none to keep the hand. Reason is only set on
+// concessions the engine records for forfeits.
+type Action struct {
+	Type       ActionType `json:"type"`
+	CardID     string     `json:"card_id,omitempty"`
+	AttackerID string     `json:"attacker_id,omitempty"`
+	TargetID   string     `json:"target_id,omitempty"`
+	Phase      GamePhase  `json:"phase,omitempty"`
+	CardIDs    []string   `json:"card_ids,omitempty"`
+	Reason     EndReason  `json:"reason,omitempty"`
+}
+
+// Equal reports whether two actions describe the same move
+func (a Action) Equal(other Action) bool {
+	return a.Type == other.Type && a.CardID == other.CardID && a.AttackerID == other.AttackerID &&
+		a.TargetID == other.TargetID && a.Phase == other.Phase && a.Reason == other.Reason &&
+		slices.Equal(a.CardIDs, other.CardIDs)
+}
+
+// String returns the action in the command syntax used by the terminal clients
+func (a Action) String() string {
+	switch a.Type {
+	case ActionDraw:
+		return "draw"
+	case ActionPlayCard:
+		if a.TargetID != "" {
+			return fmt.Sprintf("play %s %s", a.CardID, a.TargetID)
+		}
+		return fmt.Sprintf("play %s", a.CardID)
+	case ActionAttack:
+		if a.TargetID == "" {
+			return fmt.Sprintf("attack %s direct", a.AttackerID)
+		}
+		return fmt.Sprintf("attack %s %s", a.AttackerID, a.TargetID)
+	case ActionChangePhase:
+		return string(a.Phase)
+	case ActionEndTurn:
+		return "end"
+	case ActionConcede:
+		return "concede"
+	case ActionRespond:
+		return fmt.Sprintf("respond %s", a.CardID)
+	case ActionPass:
+		return "pass"
+	case ActionTimeout:
+		return "timeout"
+	case ActionMulligan:
+		if len(a.CardIDs) == 0 {
+			return "keep"
+		}
+		return "mulligan " + strings.Join(a.CardIDs, " ")
+	}
+	return string(a.Type)
+}
+
+// ActionResult describes the outcome of an action accepted by the engine
+type ActionResult struct {
+	Seq       int          `json:"seq"`
+	StateHash string       `json:"state_hash"`
+	Action    Action       `json:"action"`
+	Events    []Event      `json:"events,omitempty"`
+	Message   string       `json:"message"`
+	GameOver  bool         `json:"game_over"`
+	Winner    string       `json:"winner,omitempty"`
+	Result    *MatchResult `json:"result,omitempty"`
+}
+
+// Apply validates and executes an action on behalf of playerID. It is the
+// single entry point through which every move changes a game. A player may
+// concede at any time. During the mulligan phase both players may
+// mulligan, in either order. Otherwise while the stack is empty every other
+// action needs it to be their turn; while it is not, only the player with
+// priority may respond or pass. Under time controls a player whose clock
+// has run out can only concede.
+func (be *BattleEngine) Apply(gameID, playerID string, action Action) (*ActionResult, error) {
+	return be.ApplyAt(gameID, playerID, anyVersion, action)
+}
+
+// ApplyAt is Apply for a move decided on the snapshot with the given
+// Version. It is rejected if anything has happened in the game since, so a
+// move never lands on a state it was not meant for.
+func (be *BattleEngine) ApplyAt(gameID, playerID string, version int, action Action) (*ActionResult, error) {
+	if action.Type == ActionConcede && action.Reason != "" && action.Reason != ReasonConcession {
+		return nil, fmt.Errorf("players can only concede")
+	}
+	if action.Type == ActionTimeout {
+		return nil, fmt.Errorf("timeouts are issued by the engine")
+	}
+	return be.apply(gameID, playerID, version, action)
+}
+
+// anyVersion applies an action to whatever the game's current state is
+const anyVersion = -1
+
+func (be *BattleEngine) apply(gameID, playerID string, version int, action Action) (*ActionResult, error) {
+	var result *ActionResult
+	err := be.run(gameID, func(m *match) (err error) {
+		if version != anyVersion && version != m.game.Version {
+			return fmt.Errorf("game has moved on since version %d", version)
+		}
+		result, err = be.applyAction(m.game, playerID, action, be.elapsed(m.game))
+		return err
+	})
+	return result, err
+}
+
+// applyAction executes an action that took elapsed on the running clock.
+// Replays pass the recorded elapsed time so clocks come out the same.
+func (be *BattleEngine) applyAction(game *GameState, playerID string, action Action, elapsed time.Duration) (*ActionResult, error) {
+	if game.GameOver {
+		return nil, fmt.Errorf("game is over")
+	}
+
+	player := be.getPlayer(game, playerID)
+	if player == nil {
+		return nil, fmt.Errorf("player not found")
+	}
+
+	if err := be.checkPriority(game, playerID, action.Type); err != nil {
+		return nil, err
+	}
+
+	clock := be.saveClock(game)
+	if !be.spendClock(game, elapsed) && action.Type != ActionConcede && action.Type != ActionTimeout {
+		clock.restore(game)
+		return nil, fmt.Errorf("out of time")
+	}
+
+	game.events.startAction()
+	var err error
+	switch action.Type {
+	case ActionDraw:
+		err = be.drawCard(game, player)
+	case ActionPlayCard:
+		err = be.playCard(game, player, action.CardID, action.TargetID)
+	case ActionAttack:
+		err = be.attack(game, player, action.AttackerID, action.TargetID)
+	case ActionChangePhase:
+		err = be.changePhase(game, action.Phase)
+	case ActionEndTurn:
+		err = be.endTurn(game, player)
+	case ActionConcede:
+		err = be.concede(game, player, action.Reason)
+	case ActionRespond:
+		err = be.respond(game, player, action.CardID)
+	case ActionPass:
+		err = be.pass(game)
+	case ActionMulligan:
+		err = be.mulligan(game, player, action.CardIDs)
+	case ActionTimeout:
+		err = be.timeout(game, player)
+	default:
+		err = fmt.Errorf("unknown action type %q", action.Type)
+	}
+	if err != nil {
+		clock.restore(game)
+		return nil, err
+	}
+
+	// Auras may have weakened; anything left without defense is destroyed
+	if !game.GameOver {
+		be.removeDestroyed(game, game.Player1)
+		be.removeDestroyed(game, game.Player2)
+	}
+
+	if dropped := game.events.dropped; dropped > 0 {
+		game.LastAction = fmt.Sprintf("%s (event limit reached, %d events dropped)", game.LastAction, dropped)
+	}
+
+	// Check win condition
+	be.checkWinner(game)
+
+	game.Version = len(game.log) + 1
+	game.lastActivity = be.now()
+	be.restartClock(game)
+	entry := be.record(game, playerID, action, elapsed)
+	be.notifyStateChange(game)
+
+	return &ActionResult{
+		Seq:       entry.Seq,
+		StateHash: entry.StateHash,
+		Action:    action,
+		Events:    entry.Events,
+		Message:   entry.Result,
+		GameOver:  game.GameOver,
+		Winner:    game.Winner,
+		Result:    game.Result.copy(),
+	}, nil
+}
@@ -0,0 +1,264 @@
+package battle
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// Archetype represents card archetypes
+type Archetype string
+
+const (
+	ArchetypeEgyptian Archetype = "egyptian"
+	ArchetypeGreek    Archetype = "greek"
+	ArchetypeNeutral  Archetype = "neutral"
+)
+
+// Stat names a card stat a synergy scales
+type Stat string
+
+const (
+	StatAttack  Stat = "attack"
+	StatDefense Stat = "defense"
+)
+
+// SynergyKind identifies how cards of an archetype reward each other
+type SynergyKind string
+
+const (
+	// SynergyScale raises Stat of each card by Percent of its base value for
+	// every other card of the archetype on its controller's field. A zero
+	// Percent uses the rule set's ArchetypeBonusPercent.
+	SynergyScale SynergyKind = "scale"
+	// SynergyThreshold gives each card Attack and Defense while its
+	// controller has at least Threshold cards of the archetype on the field
+	SynergyThreshold SynergyKind = "threshold"
+	// SynergyTriggered resolves Trigger once for the controller whenever its
+	// event occurs while they have at least Threshold cards of the archetype
+	// on the field
+	SynergyTriggered SynergyKind = "triggered"
+)
+
+// Synergy is a rule describing how cards of one archetype work together.
+// Only the fields used by its Kind are meaningful.
+type Synergy struct {
+	Kind      SynergyKind       `json:"kind"`
+	Stat      Stat              `json:"stat,omitempty"`
+	Percent   int               `json:"percent,omitempty"`
+	Threshold int               `json:"threshold,omitempty"`
+	Attack    int               `json:"attack,omitempty"`
+	Defense   int               `json:"defense,omitempty"`
+	Trigger   *TriggeredAbility `json:"trigger,omitempty"`
+}
+
+// ArchetypeDef describes an archetype and its synergies
+type ArchetypeDef struct {
+	ID          Archetype `json:"id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description,omitempty"`
+	Synergies   []Synergy `json:"synergies,omitempty"`
+}
+
+// validate reports what is wrong with the definition, if anything
+func (d ArchetypeDef) validate() error {
+	if d.ID == "" {
+		return fmt.Errorf("archetype needs an ID")
+	}
+
+	for _, s := range d.Synergies {
+		switch s.Kind {
+		case SynergyScale:
+			if s.Stat != StatAttack && s.Stat != StatDefense {
+				return fmt.Errorf("archetype %s: scale synergy needs a stat", d.ID)
+			}
+		case SynergyThreshold:
+			if s.Threshold < 1 {
+				return fmt.Errorf("archetype %s: threshold synergy needs a threshold of at least 1", d.ID)
+			}
+		case SynergyTriggered:
+			if s.Trigger == nil {
+				return fmt.Errorf("archetype %s: triggered synergy needs a trigger", d.ID)
+			}
+		default:
+			return fmt.Errorf("archetype %s: unknown synergy kind %q", d.ID, s.Kind)
+		}
+	}
+	return nil
+}
+
+// Describe returns the synergies of the archetype in one line
+func (d ArchetypeDef) Describe(rules RuleSet) string {
+	parts := make([]string, len(d.Synergies))
+	for i, s := range d.Synergies {
+		parts[i] = s.describe(d.Name, rules)
+	}
+	return strings.Join(parts, "; ")
+}
+
+func (s Synergy) describe(name string, rules RuleSet) string {
+	switch s.Kind {
+	case SynergyScale:
+		stat := "ATK"
+		if s.Stat == StatDefense {
+			stat = "DEF"
+		}
+		return fmt.Sprintf("+%d%% %s for each other %s card", s.percent(rules), stat, name)
+	case SynergyThreshold:
+		return fmt.Sprintf("+%d ATK / +%d DEF with %d or more %s cards", s.Attack, s.Defense, s.Threshold, name)
+	case SynergyTriggered:
+		return fmt.Sprintf("on %s with %d or more %s cards: %s", s.Trigger.On, max(s.Threshold, 1), name, s.Trigger.Effect)
+	}
+	return string(s.Kind)
+}
+
+func (s Synergy) percent(rules RuleSet) int {
+	if s.Percent == 0 {
+		return rules.ArchetypeBonusPercent
+	}
+	return s.Percent
+}
+
+// archetypeRegistry holds the archetypes known to every engine
+type archetypeRegistry struct {
+	mu    sync.RWMutex
+	defs  map[Archetype]ArchetypeDef
+	order []Archetype
+}
+
+var archetypes = &archetypeRegistry{defs: make(map[Archetype]ArchetypeDef)}
+
+func init() {
+	for _, def := range defaultArchetypes() {
+		if err := RegisterArchetype(def); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// defaultArchetypes returns the archetypes the game ships with
+func defaultArchetypes() []ArchetypeDef {
+	return []ArchetypeDef{
+		{
+			ID:          ArchetypeEgyptian,
+			Name:        "Egyptian Gods",
+			Description: "Attack focused",
+			Synergies:   []Synergy{{Kind: SynergyScale, Stat: StatAttack}},
+		},
+		{
+			ID:          ArchetypeGreek,
+			Name:        "Greek Gods",
+			Description: "Defense focused",
+			Synergies:   []Synergy{{Kind: SynergyScale, Stat: StatDefense}},
+		},
+		{
+			ID:          ArchetypeNeutral,
+			Name:        "Neutral",
+			Description: "Fits in any deck",
+		},
+	}
+}
+
+// RegisterArchetype adds an archetype so cards of it get its synergies.
+// Archetypes are registered once, usually from an init function, before any
+// match that uses them is created.
+func RegisterArchetype(def ArchetypeDef) error {
+	if err := def.validate(); err != nil {
+		return err
+	}
+
+	archetypes.mu.Lock()
+	defer archetypes.mu.Unlock()
+
+	if _, exists := archetypes.defs[def.ID]; exists {
+		return fmt.Errorf("archetype %s is already registered", def.ID)
+	}
+	if def.Name == "" {
+		def.Name = string(def.ID)
+	}
+
+	archetypes.defs[def.ID] = def
+	archetypes.order = append(archetypes.order, def.ID)
+	return nil
+}
+
+// LookupArchetype returns the definition of a registered archetype
+func LookupArchetype(id Archetype) (ArchetypeDef, bool) {
+	archetypes.mu.RLock()
+	defer archetypes.mu.RUnlock()
+
+	def, ok := archetypes.defs[id]
+	return def, ok
+}
+
+// RegisteredArchetypes returns every registered archetype in registration order
+func RegisteredArchetypes() []ArchetypeDef {
+	archetypes.mu.RLock()
+	defer archetypes.mu.RUnlock()
+
+	defs := make([]ArchetypeDef, len(archetypes.order))
+	for i, id := range archetypes.order {
+		defs[i] = archetypes.defs[id]
+	}
+	return defs
+}
+
+// archetypeAura returns the attack and defense a field card gains from the
+// synergies of its archetype, and the scaling bonus as a fraction of its
+// base stats
+func archetypeAura(rules RuleSet, player *Player, card Card) (int, int, float32) {
+	def, ok := LookupArchetype(card.Archetype)
+	if !ok {
+		return 0, 0, 0
+	}
+
+	count := countArchetype(player, card.Archetype)
+	attack, defense, percent := 0, 0, 0
+
+	for _, s := range def.Synergies {
+		switch s.Kind {
+		case SynergyScale:
+			// The card itself does not count towards its own bonus
+			scaled := (count - 1) * s.percent(rules)
+			percent += scaled
+			if s.Stat == StatAttack {
+				attack += card.BaseAttack * scaled / 100
+			} else {
+				defense += card.BaseDefense * scaled / 100
+			}
+		case SynergyThreshold:
+			if count >= s.Threshold {
+				attack += s.Attack
+				defense += s.Defense
+			}
+		}
+	}
+
+	return attack, defense, float32(percent) / 100
+}
+
+// runSynergyTriggers resolves the triggered synergies of owner's archetypes
+// that react to event
+func (be *BattleEngine) runSynergyTriggers(game *GameState, owner *Player, event Event) {
+	for _, def := range RegisteredArchetypes() {
+		for _, s := range def.Synergies {
+			if s.Kind != SynergyTriggered || !s.Trigger.matches(event, owner.ID) {
+				continue
+			}
+			if countArchetype(owner, def.ID) >= max(s.Threshold, 1) {
+				be.resolveEffect(game, owner, s.Trigger.Effect, &event)
+			}
+		}
+	}
+}
+
+// countArchetype counts the cards of an archetype on the player's field
+func countArchetype(player *Player, archetype Archetype) int {
+	count := 0
+	for _, card := range player.Field {
+		if card.Archetype == archetype {
+			count++
+		}
+	}
+	return count
+}
@@ -0,0 +1,89 @@
+package battle
+
+import "fmt"
+
+// CardType decides what happens to a card when it is played
+type CardType string
+
+const (
+	// CardCreature is placed on the field where it can attack and be attacked
+	CardCreature CardType = "creature"
+	// CardSpell resolves its effects and goes straight to the graveyard
+	CardSpell CardType = "spell"
+	// CardEquipment attaches to a creature on its controller's field and adds
+	// its own Attack and Defense to that creature
+	CardEquipment CardType = "equipment"
+	// CardTrap is set face-down and can be flipped once, in response to a
+	// move on the stack that one of its triggers reacts to
+	CardTrap CardType = "trap"
+	// CardInstant can only be played in response to a move on the stack
+	CardInstant CardType = "instant"
+)
+
+// IsCreature reports whether the card is a creature. Cards without a type are creatures.
+func (c Card) IsCreature() bool {
+	return c.Type == CardCreature || c.Type == ""
+}
+
+// checkCardType reports why a card of its type cannot be played, if it cannot
+func (be *BattleEngine) checkCardType(game *GameState, player *Player, card Card, targetID string) error {
+	switch card.Type {
+	case CardSpell:
+		return nil
+	case CardInstant:
+		return fmt.Errorf("instants can only be played in response")
+	case CardEquipment:
+		if findCard(player.Field, targetID) == -1 {
+			return fmt.Errorf("equipment needs a card on your field to attach to")
+		}
+		return nil
+	case CardTrap:
+		if game.Rules.MaxFieldSize > 0 && len(player.Traps) >= game.Rules.MaxFieldSize {
+			return fmt.Errorf("trap zone is full")
+		}
+		return nil
+	}
+
+	if game.Rules.MaxFieldSize > 0 && len(player.Field) >= game.Rules.MaxFieldSize {
+		return fmt.Errorf("field is full")
+	}
+	return nil
+}
+
+// castSpell resolves a spell and puts it in the graveyard
+func (be *BattleEngine) castSpell(game *GameState, player *Player, card Card) {
+	player.Graveyard = append(player.Graveyard, card)
+
+	be.applyCardEffect(game, player, card)
+	be.emit(game, Event{Type: EventCardPlayed, PlayerID: player.ID, CardID: card.ID, InstanceID: card.InstanceID, CardName: card.Name})
+
+	game.LastAction = fmt.Sprintf("%s cast %s", player.ID, card.Name)
+}
+
+// equip attaches an equipment card to a creature on the player's field. If
+// the creature left the field before the equipment resolved, the equipment
+// goes to the graveyard.
+func (be *BattleEngine) equip(game *GameState, player *Player, card Card, targetID string) {
+	targetIndex := findCard(player.Field, targetID)
+	if targetIndex == -1 {
+		player.Graveyard = append(player.Graveyard, card)
+		game.LastAction = fmt.Sprintf("%s fizzled", card.Name)
+		return
+	}
+
+	target := &player.Field[targetIndex]
+	target.Attached = append(target.Attached, card)
+	targetName := target.Name
+	be.recomputeStats(game)
+
+	be.applyCardEffect(game, player, card)
+	be.emit(game, Event{Type: EventCardPlayed, PlayerID: player.ID, CardID: card.ID, InstanceID: card.InstanceID, CardName: card.Name})
+
+	game.LastAction = fmt.Sprintf("%s equipped %s with %s", player.ID, targetName, card.Name)
+}
+
+// setTrap places a trap face-down. Its name stays hidden until it fires.
+func (be *BattleEngine) setTrap(game *GameState, player *Player, card Card) {
+	player.Traps = append(player.Traps, card)
+	game.LastAction = fmt.Sprintf("%s set a trap", player.ID)
+}
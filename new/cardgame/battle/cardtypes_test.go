@@ -0,0 +1,116 @@
+package battle_test
+
+import (
+	"strings"
+	"testing"
+
+	"cardgame/battle"
+)
+
+var (
+	// pitfall destroys the first card that attacks its owner
+	pitfall = battle.Card{ID: "pitfall", Type: battle.CardTrap, Name: "Pitfall", Cost: 1, Triggers: []battle.TriggeredAbility{{
+		On:     battle.EventAttackDeclared,
+		Scope:  battle.ScopeOpponent,
+		Effect: battle.CardEffect{Kind: battle.EffectDamage, Amount: 9999, Target: battle.TargetTriggeringCard},
+	}}}
+	// plate gives the creature it is attached to +300/+1000
+	plate = battle.Card{ID: "plate", Type: battle.CardEquipment, Name: "Plate", Attack: 300, Defense: 1000, Cost: 1}
+)
+
+func TestSpellResolvesIntoGraveyard(t *testing.T) {
+	jolt := battle.Card{ID: "jolt", Type: battle.CardSpell, Name: "Jolt", Cost: 1, Effects: spark.Effects}
+	be := battle.NewBattleEngine()
+	g := newScriptedMatch(t, be, []battle.Card{jolt}, nil)
+	hp := g.Player2.HP
+
+	s := play(t, be, g.ID, "A", "Jolt")
+	if s.Player2.HP != hp-100 {
+		t.Fatalf("Jolt did not resolve")
+	}
+	if len(s.Player1.Field) != 0 || len(s.Player1.Graveyard) != 1 || s.Player1.Graveyard[0].Name != "Jolt" {
+		t.Fatalf("Jolt did not go to the graveyard")
+	}
+}
+
+func TestEquipmentAddsItsStats(t *testing.T) {
+	flood := battle.Card{ID: "flood", Type: battle.CardSpell, Name: "Flood", Cost: 1, Effects: quake.Effects}
+	be := battle.NewBattleEngine()
+	g := newScriptedMatch(t, be, []battle.Card{creature("Wolf", 500, 500), plate}, []battle.Card{flood})
+	s := play(t, be, g.ID, "A", "Wolf")
+
+	if _, err := be.Apply(g.ID, "A", battle.Action{Type: battle.ActionPlayCard, CardID: instanceID(t, s.Player1.Hand, "Plate")}); err == nil {
+		t.Fatalf("equipped without a creature to attach to")
+	}
+	mustApply(t, be, g.ID, "A", battle.Action{Type: battle.ActionPlayCard, CardID: instanceID(t, s.Player1.Hand, "Plate"), TargetID: instanceID(t, s.Player1.Field, "Wolf")})
+	s = mustApply(t, be, g.ID, "B", battle.Action{Type: battle.ActionPass})
+	wolf := s.Player1.Field[0]
+	if wolf.Attack != 800 || wolf.Defense != 1500 || len(wolf.Attached) != 1 || len(s.Player1.Field) != 1 {
+		t.Fatalf("got Wolf at %d/%d with %d attached, want 800/1500 with Plate", wolf.Attack, wolf.Defense, len(wolf.Attached))
+	}
+
+	// The equipment goes down with its creature
+	passTurn(t, be, g.ID)
+	s = play(t, be, g.ID, "B", "Flood")
+	if len(s.Player1.Field) != 0 || len(s.Player1.Graveyard) != 2 {
+		t.Fatalf("Wolf and Plate did not go to the graveyard")
+	}
+	for _, card := range s.Player1.Graveyard {
+		if card.Name == "Wolf" && (card.Attack != 500 || card.Defense != 500 || len(card.Attached) != 0) {
+			t.Fatalf("Wolf kept its equipment in the graveyard")
+		}
+	}
+}
+
+func TestEquipmentFizzlesWithoutItsCreature(t *testing.T) {
+	be := battle.NewBattleEngine()
+	g := newScriptedMatch(t, be, []battle.Card{creature("Wolf", 500, 500), plate}, []battle.Card{quake})
+	s := play(t, be, g.ID, "A", "Wolf")
+
+	// B clears A's field in response to the equipment
+	mustApply(t, be, g.ID, "A", battle.Action{Type: battle.ActionPlayCard, CardID: instanceID(t, s.Player1.Hand, "Plate"), TargetID: instanceID(t, s.Player1.Field, "Wolf")})
+	mustApply(t, be, g.ID, "B", battle.Action{Type: battle.ActionRespond, CardID: instanceID(t, g.Player2.Hand, "Quake")})
+	mustApply(t, be, g.ID, "A", battle.Action{Type: battle.ActionPass})
+	s = mustApply(t, be, g.ID, "B", battle.Action{Type: battle.ActionPass})
+
+	if !strings.Contains(s.LastAction, "fizzled") {
+		t.Fatalf("got %q, want Plate to fizzle", s.LastAction)
+	}
+	if len(s.Player1.Field) != 0 || instanceID(t, s.Player1.Graveyard, "Plate") == "" {
+		t.Fatalf("Plate did not go to the graveyard")
+	}
+}
+
+func TestTrapIsSetFaceDownAndSprung(t *testing.T) {
+	be := battle.NewBattleEngine()
+	g := newScriptedMatch(t, be, []battle.Card{pitfall}, []battle.Card{creature("Bear", 1000, 1000)})
+	s := mustApply(t, be, g.ID, "A", battle.Action{Type: battle.ActionPlayCard, CardID: instanceID(t, g.Player1.Hand, "Pitfall")})
+	if len(s.Player1.Traps) != 1 || len(s.Player1.Field) != 0 || len(s.Stack) != 0 {
+		t.Fatalf("Pitfall was not set")
+	}
+	if strings.Contains(s.LastAction, "Pitfall") {
+		t.Fatalf("setting the trap revealed it: %q", s.LastAction)
+	}
+	trap := s.Player1.Traps[0].InstanceID
+
+	// Pitfall only reacts to attacks
+	passTurn(t, be, g.ID)
+	mustApply(t, be, g.ID, "B", battle.Action{Type: battle.ActionPlayCard, CardID: instanceID(t, g.Player2.Hand, "Bear")})
+	if _, err := be.Apply(g.ID, "A", battle.Action{Type: battle.ActionRespond, CardID: trap}); err == nil {
+		t.Fatalf("Pitfall sprang on a summon")
+	}
+	s = mustApply(t, be, g.ID, "A", battle.Action{Type: battle.ActionPass})
+	mustApply(t, be, g.ID, "B", battle.Action{Type: battle.ActionChangePhase, Phase: battle.PhaseBattle})
+	hp := s.Player1.HP
+
+	mustApply(t, be, g.ID, "B", battle.Action{Type: battle.ActionAttack, AttackerID: instanceID(t, s.Player2.Field, "Bear")})
+	mustApply(t, be, g.ID, "A", battle.Action{Type: battle.ActionRespond, CardID: trap})
+	s = mustApply(t, be, g.ID, "B", battle.Action{Type: battle.ActionPass})
+	if len(s.Player2.Field) != 0 || len(s.Player1.Traps) != 0 {
+		t.Fatalf("Pitfall did not destroy Bear")
+	}
+	s = mustApply(t, be, g.ID, "A", battle.Action{Type: battle.ActionPass})
+	if s.Player1.HP != hp || instanceID(t, s.Player1.Graveyard, "Pitfall") != trap {
+		t.Fatalf("Bear's attack did not fizzle")
+	}
+}
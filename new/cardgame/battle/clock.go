@@ -0,0 +1,263 @@
+package battle
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// SetClock replaces the time source the engine measures time controls with
+func (be *BattleEngine) SetClock(now func() time.Time) {
+	be.clock.Store(&now)
+}
+
+// now reads the engine's time source
+func (be *BattleEngine) now() time.Time {
+	return (*be.clock.Load())()
+}
+
+// ClockDeadline returns when the running clock of a game runs out. It
+// reports false when no clock is running, or none of the clock holder's
+// limits applies right now.
+func (be *BattleEngine) ClockDeadline(gameID string) (time.Time, bool) {
+	var deadline time.Time
+	var running bool
+	be.run(gameID, func(m *match) error {
+		if holder, left := be.runningClock(m.game); holder != nil {
+			deadline = m.game.clockStarted.Add(left)
+			running = true
+		}
+		return nil
+	})
+	return deadline, running
+}
+
+// ExpireClock records a timeout for the player whose clock has run out.
+// Hosts call it once the deadline from ClockDeadline has passed.
+func (be *BattleEngine) ExpireClock(gameID string) (*ActionResult, error) {
+	var result *ActionResult
+	err := be.run(gameID, func(m *match) (err error) {
+		game := m.game
+		holder, left := be.runningClock(game)
+		if holder == nil {
+			return fmt.Errorf("no clock is running")
+		}
+
+		elapsed := be.elapsed(game)
+		if elapsed < left {
+			return fmt.Errorf("time has not run out")
+		}
+		result, err = be.applyAction(game, holder.ID, Action{Type: ActionTimeout}, elapsed)
+		return err
+	})
+	return result, err
+}
+
+// timed reports whether the rules use any time control
+func (r RuleSet) timed() bool {
+	return r.TurnTime > 0 || r.MatchTime > 0 || r.ResponseTime > 0
+}
+
+// mulliganTime is how long both players together get to decide on their
+// opening hands: a turn's worth of time, or failing that a response
+// window, or failing that a whole match clock
+func (r RuleSet) mulliganTime() time.Duration {
+	switch {
+	case r.TurnTime > 0:
+		return r.TurnTime
+	case r.ResponseTime > 0:
+		return r.ResponseTime
+	}
+	return r.MatchTime
+}
+
+// clockHolder returns the player whose time is running: whoever holds
+// priority, otherwise the player whose turn it is. During the mulligan
+// phase it is the first player yet to decide, though the mulligan clock
+// is shared. No clock runs once the game is over.
+func (be *BattleEngine) clockHolder(game *GameState) *Player {
+	if !game.Rules.timed() || game.GameOver {
+		return nil
+	}
+	if game.Phase == PhaseMulligan {
+		for _, player := range []*Player{game.Player1, game.Player2} {
+			if !player.MulliganDone {
+				return player
+			}
+		}
+		return nil
+	}
+	if game.Priority != "" {
+		return be.getPlayer(game, game.Priority)
+	}
+	return be.getPlayer(game, game.CurrentTurn)
+}
+
+// runningClock returns the clock holder and how long they may still take.
+// It returns nil when no limit applies to them right now, as when the stack
+// waits on a response without a response time.
+func (be *BattleEngine) runningClock(game *GameState) (*Player, time.Duration) {
+	holder := be.clockHolder(game)
+	if holder == nil {
+		return nil, 0
+	}
+	left := be.timeLeft(game, holder)
+	if left == unlimited {
+		return nil, 0
+	}
+	return holder, left
+}
+
+// unlimited is the time left when no limit applies
+const unlimited = time.Duration(math.MaxInt64)
+
+// turnClockRunning reports whether the turn time limit is counting down.
+// It is paused while the stack waits on a response. During the mulligan
+// phase it counts down the mulligan time instead.
+func turnClockRunning(game *GameState) bool {
+	if game.Phase == PhaseMulligan {
+		return game.Rules.mulliganTime() > 0
+	}
+	return game.Rules.TurnTime > 0 && game.Priority == ""
+}
+
+// timeLeft returns how long the clock holder may still take, or unlimited.
+// Match clocks do not run during the mulligan, and a response window is
+// only as long as the rules' response time.
+func (be *BattleEngine) timeLeft(game *GameState, holder *Player) time.Duration {
+	if game.Phase == PhaseMulligan {
+		return game.TurnTimeLeft
+	}
+
+	left := unlimited
+	if game.Rules.MatchTime > 0 {
+		left = holder.TimeBank
+	}
+	if turnClockRunning(game) {
+		left = min(left, game.TurnTimeLeft)
+	}
+	if game.Priority != "" && game.Rules.ResponseTime > 0 {
+		left = min(left, game.Rules.ResponseTime)
+	}
+	return left
+}
+
+// elapsed returns the time spent on the running clock since the last
+// accepted action
+func (be *BattleEngine) elapsed(game *GameState) time.Duration {
+	if be.clockHolder(game) == nil {
+		return 0
+	}
+	return be.now().Sub(game.clockStarted)
+}
+
+// spendClock charges elapsed to the clock holder, reporting whether they
+// acted before their time ran out
+func (be *BattleEngine) spendClock(game *GameState, elapsed time.Duration) bool {
+	holder := be.clockHolder(game)
+	if holder == nil {
+		return true
+	}
+
+	inTime := elapsed < be.timeLeft(game, holder)
+	if game.Rules.MatchTime > 0 && game.Phase != PhaseMulligan {
+		holder.TimeBank = max(holder.TimeBank-elapsed, 0)
+	}
+	if turnClockRunning(game) {
+		game.TurnTimeLeft = max(game.TurnTimeLeft-elapsed, 0)
+	}
+	return inTime
+}
+
+// clockState is the clock before an action, kept so a rejected action
+// costs no time
+type clockState struct {
+	holder   *Player
+	bank     time.Duration
+	turnLeft time.Duration
+}
+
+func (be *BattleEngine) saveClock(game *GameState) clockState {
+	state := clockState{holder: be.clockHolder(game), turnLeft: game.TurnTimeLeft}
+	if state.holder != nil {
+		state.bank = state.holder.TimeBank
+	}
+	return state
+}
+
+func (c clockState) restore(game *GameState) {
+	if c.holder != nil {
+		c.holder.TimeBank = c.bank
+	}
+	game.TurnTimeLeft = c.turnLeft
+}
+
+// restartClock starts timing the next action
+func (be *BattleEngine) restartClock(game *GameState) {
+	game.clockStarted = be.now()
+	game.ClockHolder = ""
+	if holder, _ := be.runningClock(game); holder != nil {
+		game.ClockHolder = holder.ID
+	}
+}
+
+// timeout handles a player running out of time. When the mulligan runs
+// out everyone yet to decide keeps their hand. An empty time bank loses
+// the match, and a response window that runs out passes. A turn that runs
+// out ends from whatever phase it was in, and the player forfeits once
+// they reach the rules' timeout limit.
+func (be *BattleEngine) timeout(game *GameState, player *Player) error {
+	if game.Phase == PhaseMulligan {
+		return be.expireMulligan(game)
+	}
+	if game.Rules.MatchTime > 0 && player.TimeBank == 0 {
+		return be.concede(game, player, ReasonTimeout)
+	}
+	if game.Priority != "" {
+		if player.ID != game.Priority || game.Rules.ResponseTime == 0 {
+			return fmt.Errorf("time has not run out")
+		}
+		if err := be.pass(game); err != nil {
+			return err
+		}
+		game.LastAction = fmt.Sprintf("%s did not respond in time", player.ID)
+		return nil
+	}
+	if player.ID != game.CurrentTurn || !turnClockRunning(game) || game.TurnTimeLeft > 0 {
+		return fmt.Errorf("time has not run out")
+	}
+
+	player.Timeouts++
+	if game.Rules.MaxTimeouts > 0 && player.Timeouts >= game.Rules.MaxTimeouts {
+		return be.concede(game, player, ReasonTimeout)
+	}
+
+	game.Phase = PhaseEnd
+	if err := be.endTurn(game, player); err != nil {
+		return err
+	}
+	game.LastAction = fmt.Sprintf("%s ran out of time", player.ID)
+	return nil
+}
+
+// expireMulligan keeps the opening hand of every player who has not
+// decided on it in time
+func (be *BattleEngine) expireMulligan(game *GameState) error {
+	if game.TurnTimeLeft > 0 {
+		return fmt.Errorf("time has not run out")
+	}
+
+	var undecided []string
+	for _, player := range []*Player{game.Player1, game.Player2} {
+		if player.MulliganDone {
+			continue
+		}
+		if err := be.mulligan(game, player, nil); err != nil {
+			return err
+		}
+		undecided = append(undecided, player.ID)
+	}
+	game.LastAction = fmt.Sprintf("mulligan time ran out; %s kept their hand", strings.Join(undecided, " and "))
+	return nil
+}
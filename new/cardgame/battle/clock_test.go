@@ -0,0 +1,143 @@
+package battle_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"cardgame/battle"
+)
+
+// fakeClock gives an engine a time that only moves when the test says so
+type fakeClock struct {
+	now time.Time
+}
+
+func newFakeClock(be *battle.BattleEngine) *fakeClock {
+	clock := &fakeClock{now: time.Unix(1000, 0)}
+	be.SetClock(func() time.Time { return clock.now })
+	return clock
+}
+
+func (c *fakeClock) advance(d time.Duration) {
+	c.now = c.now.Add(d)
+}
+
+func TestExpireClockForfeitsAfterMaxTimeouts(t *testing.T) {
+	be := battle.NewBattleEngine()
+	clock := newFakeClock(be)
+	rules := timedRules()
+	g := newMatchWithRules(t, be, rules, 3)
+	mustApply(t, be, g.ID, "A", battle.Action{Type: battle.ActionMulligan})
+	mustApply(t, be, g.ID, "B", battle.Action{Type: battle.ActionMulligan})
+
+	// Each player in turn lets their turn run out
+	for i := 0; ; i++ {
+		s := state(t, be, g.ID)
+		deadline, running := be.ClockDeadline(g.ID)
+		if !running || !deadline.Equal(clock.now.Add(rules.TurnTime)) {
+			t.Fatalf("timeout %d: deadline %v (running %v), want a turn from now", i, deadline, running)
+		}
+
+		clock.advance(rules.TurnTime - time.Second)
+		if _, err := be.ExpireClock(g.ID); err == nil || !strings.Contains(err.Error(), "not run out") {
+			t.Fatalf("timeout %d: got error %v before the deadline", i, err)
+		}
+		clock.advance(time.Second)
+		if _, err := be.ExpireClock(g.ID); err != nil {
+			t.Fatalf("timeout %d: %v", i, err)
+		}
+
+		after := state(t, be, g.ID)
+		if after.GameOver {
+			// A starts, so they reach the limit first
+			if i != 2*rules.MaxTimeouts-2 || after.Result.Loser != "A" || after.Result.Reason != battle.ReasonTimeout {
+				t.Fatalf("after %d timeouts the match ended with %+v", i+1, after.Result)
+			}
+			break
+		}
+		if after.CurrentTurn == s.CurrentTurn {
+			t.Fatalf("timeout %d: the turn did not pass", i)
+		}
+	}
+
+	if _, running := be.ClockDeadline(g.ID); running {
+		t.Fatalf("clock still running after the match ended")
+	}
+}
+
+func TestResponseWindowTimesOut(t *testing.T) {
+	be := battle.NewBattleEngine()
+	clock := newFakeClock(be)
+	g := newScriptedMatch(t, be, []battle.Card{creature("Wolf", 500, 500)}, nil, func(rules *battle.RuleSet) {
+		rules.ResponseTime = 15 * time.Second
+	})
+
+	// Without a turn time nothing is timed until a window opens
+	if _, running := be.ClockDeadline(g.ID); running {
+		t.Fatalf("clock running with only a response time and an empty stack")
+	}
+	if state(t, be, g.ID).ClockHolder != "" {
+		t.Fatalf("clock holder set with no clock running")
+	}
+
+	s := mustApply(t, be, g.ID, "A", battle.Action{Type: battle.ActionPlayCard, CardID: instanceID(t, g.Player1.Hand, "Wolf")})
+	deadline, running := be.ClockDeadline(g.ID)
+	if !running || !deadline.Equal(clock.now.Add(15*time.Second)) || s.ClockHolder != "B" {
+		t.Fatalf("response window not timed for B: deadline %v, running %v, holder %q", deadline, running, s.ClockHolder)
+	}
+
+	clock.advance(15 * time.Second)
+	if _, err := be.ExpireClock(g.ID); err != nil {
+		t.Fatalf("expiring response window: %v", err)
+	}
+	s = state(t, be, g.ID)
+	if len(s.Stack) != 0 || len(s.Player1.Field) != 1 {
+		t.Fatalf("B did not pass when the window ran out")
+	}
+	if _, running := be.ClockDeadline(g.ID); running {
+		t.Fatalf("clock still running once the stack emptied")
+	}
+}
+
+func TestUntimedRulesRunNoClock(t *testing.T) {
+	be := battle.NewBattleEngine()
+	for _, rules := range []battle.RuleSet{battle.StandardRules(), battle.ClassicRules()} {
+		g := newMatchWithRules(t, be, rules, 1)
+		if _, running := be.ClockDeadline(g.ID); running {
+			t.Fatalf("%s rules run a clock", rules.Name)
+		}
+		if _, err := be.ExpireClock(g.ID); err == nil {
+			t.Fatalf("%s rules: expired a clock that is not running", rules.Name)
+		}
+	}
+}
+
+func TestMulliganTimeoutKeepsHands(t *testing.T) {
+	be := battle.NewBattleEngine()
+	clock := newFakeClock(be)
+	rules := timedRules()
+	g := newMatchWithRules(t, be, rules, 5)
+
+	s := mustApply(t, be, g.ID, "A", battle.Action{Type: battle.ActionMulligan, CardIDs: []string{g.Player1.Hand[0].InstanceID}})
+	if s.Phase != battle.PhaseMulligan || s.ClockHolder != "B" {
+		t.Fatalf("mulligan phase ended before B decided")
+	}
+
+	clock.advance(rules.TurnTime)
+	if _, err := be.ExpireClock(g.ID); err != nil {
+		t.Fatalf("expiring mulligan: %v", err)
+	}
+	s = state(t, be, g.ID)
+	if s.Phase != battle.PhaseMain || !s.Player2.MulliganDone {
+		t.Fatalf("got phase %s, want the first turn to start", s.Phase)
+	}
+	for i, card := range s.Player2.Hand {
+		if card.InstanceID != g.Player2.Hand[i].InstanceID {
+			t.Fatalf("B's opening hand changed on timeout")
+		}
+	}
+	if s.TurnTimeLeft != rules.TurnTime {
+		t.Fatalf("first turn starts with %v, want a full %v", s.TurnTimeLeft, rules.TurnTime)
+	}
+}
@@ -0,0 +1,1113 @@
+package battle
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// CodecVersion is the version of the binary format Codec writes. Decoders
+// reject any other version.
+const CodecVersion = 1
+
+// codecMagic opens every encoded value, followed by the version and kind
+const codecMagic = "CG"
+
+// Kinds of encoded value
+const (
+	kindState  byte = 'S'
+	kindAction byte = 'A'
+	kindRecord byte = 'R'
+	kindUpdate byte = 'U'
+)
+
+// Codec encodes game states, player updates, actions and match records in
+// a compact binary form for the network, replay files and on-chain storage. Numbers are
+// varints, flags are packed into bitfields and cards found in the catalog
+// are written as a reference to their definition plus whatever about the
+// card has changed in play. Cards outside the catalog are written in full.
+//
+// Both ends must use a codec built from the same catalog; encoded states
+// carry a fingerprint of it so a mismatch is caught on decode. Decoding an
+// encoded value gives back a value with the same Hash. Decoded states are
+// snapshots: they hold no engine internals and cannot be played on.
+type Codec struct {
+	defs        []Card
+	index       map[string]int
+	fingerprint []byte
+}
+
+// NewCodec builds a codec whose catalog holds the card definitions in
+// cards. Repeated cards are fine; two different cards sharing an ID are not.
+func NewCodec(cards []Card) (*Codec, error) {
+	byID := make(map[string]Card)
+	for _, card := range cards {
+		def := card.definition()
+		if known, exists := byID[def.ID]; exists {
+			if definitionKey(known) != definitionKey(def) || !sameStats(known, def) || !sameKeywords(known.Keywords, def.Keywords) {
+				return nil, fmt.Errorf("card %s has conflicting definitions", def.ID)
+			}
+			continue
+		}
+		byID[def.ID] = def
+	}
+
+	c := &Codec{index: make(map[string]int, len(byID))}
+	for _, def := range byID {
+		c.defs = append(c.defs, def)
+	}
+	sort.Slice(c.defs, func(i, j int) bool { return c.defs[i].ID < c.defs[j].ID })
+
+	fingerprint := newCanonical("cardgame/catalog/v1")
+	fingerprint.cards(c.defs)
+	sum, _ := hex.DecodeString(fingerprint.sum())
+	c.fingerprint = sum[:8]
+
+	for i, def := range c.defs {
+		c.index[def.ID] = i
+	}
+	return c, nil
+}
+
+// EncodeState encodes a game state
+func (c *Codec) EncodeState(g *GameState) []byte {
+	e := c.newEncoder(kindState, g.Player1.ID, g.Player2.ID)
+	e.str(g.ID)
+	e.str(g.Player1.ID)
+	e.str(g.Player2.ID)
+	e.player(g.Player1)
+	e.player(g.Player2)
+	e.ref(g.CurrentTurn)
+	e.int(int64(g.TurnCount))
+	e.str(string(g.Phase))
+	e.ref(g.Winner)
+	e.flags(g.GameOver, g.Result != nil)
+	e.str(g.LastAction)
+	e.int(int64(g.Version))
+	e.int(g.Seed)
+	e.rules(g.Rules)
+	if g.Result != nil {
+		e.result(g.Result)
+	}
+	e.stack(g.Stack)
+	e.ref(g.Priority)
+	e.int(int64(g.TurnTimeLeft))
+	e.ref(g.ClockHolder)
+	return e.buf
+}
+
+// DecodeState decodes a game state written by EncodeState
+func (c *Codec) DecodeState(data []byte) (*GameState, error) {
+	d, err := c.newDecoder(data, kindState)
+	if err != nil {
+		return nil, err
+	}
+
+	g := &GameState{ID: d.str()}
+	d.players(d.str(), d.str())
+	g.Player1 = d.player(d.player1)
+	g.Player2 = d.player(d.player2)
+	g.CurrentTurn = d.ref()
+	g.TurnCount = int(d.int())
+	g.Phase = GamePhase(d.str())
+	g.Winner = d.ref()
+	flags := d.uint()
+	g.GameOver = flags&(1<<0) != 0
+	g.LastAction = d.str()
+	g.Version = int(d.int())
+	g.Seed = d.int()
+	g.Rules = d.rules()
+	if flags&(1<<1) != 0 {
+		g.Result = d.result()
+	}
+	g.Stack = d.stack()
+	g.Priority = d.ref()
+	g.TurnTimeLeft = time.Duration(d.int())
+	g.ClockHolder = d.ref()
+	return g, d.finish()
+}
+
+// Catalog returns the card definitions the codec refers to cards by. The
+// other end of a connection builds its codec from them.
+func (c *Codec) Catalog() []Card {
+	return copyCards(c.defs)
+}
+
+// Update is a game update as sent to one player: their view of the game,
+// the moves open to them and the hash of the view the server sent
+type Update struct {
+	View     *GameView
+	Legal    []Action
+	ViewHash string
+}
+
+// EncodeUpdate encodes a player's view of a game along with the moves open
+// to them
+func (c *Codec) EncodeUpdate(view *GameView, legal []Action) []byte {
+	e := c.newEncoder(kindUpdate, view.Player1.ID, view.Player2.ID)
+	e.str(view.ID)
+	e.str(view.Player1.ID)
+	e.str(view.Player2.ID)
+	e.ref(view.ViewerID)
+	e.playerView(view.Player1)
+	e.playerView(view.Player2)
+	e.ref(view.CurrentTurn)
+	e.int(int64(view.TurnCount))
+	e.str(string(view.Phase))
+	e.ref(view.Winner)
+	e.flags(view.GameOver, view.Result != nil)
+	e.str(view.LastAction)
+	e.int(int64(view.Version))
+	e.rules(view.Rules)
+	if view.Result != nil {
+		e.result(view.Result)
+	}
+	e.stack(view.Stack)
+	e.ref(view.Priority)
+	e.int(int64(view.TurnTimeLeft))
+	e.ref(view.ClockHolder)
+
+	e.uint(uint64(len(legal)))
+	for _, action := range legal {
+		e.action(action)
+	}
+	hash, _ := hex.DecodeString(view.Hash())
+	e.str(string(hash))
+	return e.buf
+}
+
+// DecodeUpdate decodes a player update written by EncodeUpdate
+func (c *Codec) DecodeUpdate(data []byte) (*Update, error) {
+	d, err := c.newDecoder(data, kindUpdate)
+	if err != nil {
+		return nil, err
+	}
+
+	view := &GameView{ID: d.str()}
+	d.players(d.str(), d.str())
+	view.ViewerID = d.ref()
+	view.Player1 = d.playerView(d.player1)
+	view.Player2 = d.playerView(d.player2)
+	view.CurrentTurn = d.ref()
+	view.TurnCount = int(d.int())
+	view.Phase = GamePhase(d.str())
+	view.Winner = d.ref()
+	flags := d.uint()
+	view.GameOver = flags&(1<<0) != 0
+	view.LastAction = d.str()
+	view.Version = int(d.int())
+	view.Rules = d.rules()
+	if flags&(1<<1) != 0 {
+		view.Result = d.result()
+	}
+	view.Stack = d.stack()
+	view.Priority = d.ref()
+	view.TurnTimeLeft = time.Duration(d.int())
+	view.ClockHolder = d.ref()
+
+	update := &Update{View: view, Legal: make([]Action, d.count())}
+	for i := range update.Legal {
+		update.Legal[i] = d.action()
+	}
+	update.ViewHash = hex.EncodeToString([]byte(d.str()))
+	return update, d.finish()
+}
+
+// EncodeRecord encodes a closed match, replay log included
+func (c *Codec) EncodeRecord(r *MatchRecord) []byte {
+	e := c.newEncoder(kindRecord, r.Setup.Player1ID, r.Setup.Player2ID)
+	e.str(r.Setup.Player1ID)
+	e.str(r.Setup.Player2ID)
+
+	info := r.Info
+	e.str(info.ID)
+	e.ref(info.Player1ID)
+	e.ref(info.Player2ID)
+	e.str(string(info.Status))
+	e.str(info.Rules)
+	e.int(int64(info.TurnCount))
+	e.str(string(info.Phase))
+	e.int(int64(info.Actions))
+	e.time(info.CreatedAt)
+	e.time(info.LastActivity)
+	e.flags(info.Result != nil)
+	if info.Result != nil {
+		e.result(info.Result)
+	}
+
+	setup := r.Setup
+	e.str(setup.GameID)
+	e.cards(setup.Deck1)
+	e.cards(setup.Deck2)
+	e.rules(setup.Rules)
+	e.int(setup.Seed)
+
+	e.uint(uint64(len(r.Log)))
+	for _, entry := range r.Log {
+		hash, _ := hex.DecodeString(entry.StateHash)
+		e.int(int64(entry.Seq))
+		e.ref(entry.PlayerID)
+		e.action(entry.Action)
+		e.uint(uint64(len(entry.Events)))
+		for _, event := range entry.Events {
+			e.event(event)
+		}
+		e.str(entry.Result)
+		e.int(int64(entry.Elapsed))
+		e.str(string(hash))
+	}
+	return e.buf
+}
+
+// DecodeRecord decodes a match record written by EncodeRecord
+func (c *Codec) DecodeRecord(data []byte) (*MatchRecord, error) {
+	d, err := c.newDecoder(data, kindRecord)
+	if err != nil {
+		return nil, err
+	}
+	d.players(d.str(), d.str())
+
+	r := &MatchRecord{}
+	info := &r.Info
+	info.ID = d.str()
+	info.Player1ID = d.ref()
+	info.Player2ID = d.ref()
+	info.Status = MatchStatus(d.str())
+	info.Rules = d.str()
+	info.TurnCount = int(d.int())
+	info.Phase = GamePhase(d.str())
+	info.Actions = int(d.int())
+	info.CreatedAt = d.time()
+	info.LastActivity = d.time()
+	if d.uint()&(1<<0) != 0 {
+		info.Result = d.result()
+	}
+
+	setup := &r.Setup
+	setup.GameID = d.str()
+	setup.Player1ID = d.player1
+	setup.Player2ID = d.player2
+	setup.Deck1 = d.cards()
+	setup.Deck2 = d.cards()
+	setup.Rules = d.rules()
+	setup.Seed = d.int()
+
+	n := d.count()
+	r.Log = make([]LogEntry, n)
+	for i := range r.Log {
+		entry := &r.Log[i]
+		entry.Seq = int(d.int())
+		entry.PlayerID = d.ref()
+		entry.Action = d.action()
+		if events := d.count(); events > 0 {
+			entry.Events = make([]Event, events)
+			for j := range entry.Events {
+				entry.Events[j] = d.event()
+			}
+		}
+		entry.Result = d.str()
+		entry.Elapsed = time.Duration(d.int())
+		entry.StateHash = hex.EncodeToString([]byte(d.str()))
+	}
+	return r, d.finish()
+}
+
+// EncodeAction encodes an action. Actions hold no cards, so they need no
+// catalog.
+func EncodeAction(a Action) []byte {
+	e := &encoder{buf: []byte(codecMagic)}
+	e.buf = append(e.buf, CodecVersion, kindAction)
+	e.action(a)
+	return e.buf
+}
+
+// DecodeAction decodes an action written by EncodeAction
+func DecodeAction(data []byte) (Action, error) {
+	d, err := readHeader(data, kindAction)
+	if err != nil {
+		return Action{}, err
+	}
+	a := d.action()
+	return a, d.finish()
+}
+
+// definition strips a card down to what it is printed with
+func (c Card) definition() Card {
+	return Card{
+		ID:        c.ID,
+		Type:      c.Type,
+		Name:      c.Name,
+		Archetype: c.Archetype,
+		Attack:    c.Attack,
+		Defense:   c.Defense,
+		Cost:      c.Cost,
+		Effect:    c.Effect,
+		Effects:   c.Effects,
+		Triggers:  c.Triggers,
+		Keywords:  c.Keywords,
+	}
+}
+
+// definitionKey identifies the parts of a card that never change in play.
+// Stats and keywords can, so a card still matches its definition with them
+// changed and only the changes are written.
+func definitionKey(card Card) string {
+	c := newCanonical("cardgame/definition/v1")
+	c.str(card.ID)
+	c.str(string(card.Type))
+	c.str(card.Name)
+	c.str(string(card.Archetype))
+	c.int(int64(card.Cost))
+	c.str(card.Effect)
+	c.int(int64(len(card.Effects)))
+	for _, effect := range card.Effects {
+		c.effect(effect)
+	}
+	c.int(int64(len(card.Triggers)))
+	for _, trigger := range card.Triggers {
+		c.str(string(trigger.On))
+		c.str(string(trigger.Scope))
+		c.effect(trigger.Effect)
+	}
+	return c.sum()
+}
+
+func sameStats(a, b Card) bool {
+	return a.Attack == b.Attack && a.Defense == b.Defense
+}
+
+func sameKeywords(a, b []Keyword) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// Card flags
+const (
+	cardInline = 1 << iota
+	cardStats
+	cardKeywords
+	cardInstance
+	cardAttacked
+	cardEntered
+	cardBase
+	cardDamage
+	cardModifiers
+	cardAttached
+	cardPrintedKeywords
+	cardBaseKeywords
+)
+
+// encoder appends values to buf. Player IDs are written as references to
+// the two players of the match where possible.
+type encoder struct {
+	buf     []byte
+	codec   *Codec
+	player1 string
+	player2 string
+}
+
+func (c *Codec) newEncoder(kind byte, player1, player2 string) *encoder {
+	e := &encoder{buf: []byte(codecMagic), codec: c, player1: player1, player2: player2}
+	e.buf = append(e.buf, CodecVersion, kind)
+	e.buf = append(e.buf, c.fingerprint...)
+	return e
+}
+
+func (e *encoder) uint(n uint64) {
+	e.buf = binary.AppendUvarint(e.buf, n)
+}
+
+func (e *encoder) int(n int64) {
+	e.buf = binary.AppendVarint(e.buf, n)
+}
+
+func (e *encoder) str(s string) {
+	e.uint(uint64(len(s)))
+	e.buf = append(e.buf, s...)
+}
+
+// flags packs booleans into a bitfield, the first in the lowest bit
+func (e *encoder) flags(bits ...bool) {
+	var n uint64
+	for i, bit := range bits {
+		if bit {
+			n |= 1 << i
+		}
+	}
+	e.uint(n)
+}
+
+func (e *encoder) time(t time.Time) {
+	e.flags(!t.IsZero())
+	if !t.IsZero() {
+		e.int(t.UnixNano())
+	}
+}
+
+// ref writes a player ID: 0 for none, 1 and 2 for the players, 3 and the
+// ID itself for anything else
+func (e *encoder) ref(id string) {
+	switch id {
+	case "":
+		e.uint(0)
+	case e.player1:
+		e.uint(1)
+	case e.player2:
+		e.uint(2)
+	default:
+		e.uint(3)
+		e.str(id)
+	}
+}
+
+func (e *encoder) player(p *Player) {
+	e.str(p.Name)
+	e.int(int64(p.HP))
+	e.int(int64(p.Mana))
+	e.int(int64(p.MaxMana))
+	e.cards(p.Deck)
+	e.cards(p.Hand)
+	e.cards(p.Field)
+	e.cards(p.Graveyard)
+	e.cards(p.Traps)
+	e.bonus(p.ArchetypeBonus)
+	e.int(int64(p.Fatigue))
+	e.flags(p.MulliganDone)
+	e.int(int64(p.TimeBank))
+	e.int(int64(p.Timeouts))
+}
+
+// playerView writes a player as seen by the viewer. Hidden zones are
+// already reduced to counts.
+func (e *encoder) playerView(p *PlayerView) {
+	e.str(p.Name)
+	e.int(int64(p.HP))
+	e.int(int64(p.Mana))
+	e.int(int64(p.MaxMana))
+	e.cards(p.Hand)
+	e.int(int64(p.HandCount))
+	e.int(int64(p.DeckCount))
+	e.cards(p.Field)
+	e.cards(p.Graveyard)
+	e.cards(p.Traps)
+	e.int(int64(p.TrapCount))
+	e.bonus(p.ArchetypeBonus)
+	e.int(int64(p.Fatigue))
+	e.flags(p.MulliganDone)
+	e.int(int64(p.TimeBank))
+	e.int(int64(p.Timeouts))
+}
+
+// bonus writes the archetype bonuses sorted by archetype, each as the bits
+// of its float
+func (e *encoder) bonus(bonus map[Archetype]float32) {
+	archetypes := make([]string, 0, len(bonus))
+	for archetype := range bonus {
+		archetypes = append(archetypes, string(archetype))
+	}
+	sort.Strings(archetypes)
+	e.uint(uint64(len(archetypes)))
+	for _, archetype := range archetypes {
+		e.str(archetype)
+		e.buf = binary.LittleEndian.AppendUint32(e.buf, math.Float32bits(bonus[Archetype(archetype)]))
+	}
+}
+
+func (e *encoder) cards(cards []Card) {
+	e.uint(uint64(len(cards)))
+	for _, card := range cards {
+		e.card(card)
+	}
+}
+
+// card writes a reference to the card's definition, or the definition
+// itself, followed by the state the card picked up in play
+func (e *encoder) card(card Card) {
+	def, inCatalog := e.codec.lookup(card)
+
+	var flags uint64
+	set := func(flag uint64, on bool) {
+		if on {
+			flags |= flag
+		}
+	}
+	set(cardInline, !inCatalog)
+	set(cardStats, !sameStats(card, def))
+	set(cardKeywords, !sameKeywords(card.Keywords, def.Keywords))
+	set(cardInstance, card.InstanceID != "")
+	set(cardAttacked, card.HasAttacked)
+	set(cardEntered, card.EnteredTurn != 0)
+	set(cardBase, card.BaseAttack != 0 || card.BaseDefense != 0)
+	set(cardDamage, card.Damage != 0)
+	set(cardModifiers, len(card.Modifiers) > 0)
+	set(cardAttached, len(card.Attached) > 0)
+
+	// A field card's base keywords are almost always the printed ones
+	printed := sameKeywords(card.BaseKeywords, def.Keywords)
+	set(cardPrintedKeywords, len(card.BaseKeywords) > 0 && printed)
+	set(cardBaseKeywords, len(card.BaseKeywords) > 0 && !printed)
+	e.uint(flags)
+
+	if inCatalog {
+		e.uint(uint64(e.codec.index[card.ID]))
+	} else {
+		e.definition(card)
+	}
+	if flags&cardStats != 0 {
+		e.int(int64(card.Attack))
+		e.int(int64(card.Defense))
+	}
+	if flags&cardKeywords != 0 {
+		e.keywords(card.Keywords)
+	}
+	if flags&cardInstance != 0 {
+		e.str(card.InstanceID)
+	}
+	if flags&cardEntered != 0 {
+		e.int(int64(card.EnteredTurn))
+	}
+	if flags&cardBase != 0 {
+		e.int(int64(card.BaseAttack))
+		e.int(int64(card.BaseDefense))
+	}
+	if flags&cardDamage != 0 {
+		e.int(int64(card.Damage))
+	}
+	if flags&cardModifiers != 0 {
+		e.uint(uint64(len(card.Modifiers)))
+		for _, modifier := range card.Modifiers {
+			e.modifier(modifier)
+		}
+	}
+	if flags&cardAttached != 0 {
+		e.cards(card.Attached)
+	}
+	if flags&cardBaseKeywords != 0 {
+		e.keywords(card.BaseKeywords)
+	}
+}
+
+// definition writes a card definition in full
+func (e *encoder) definition(card Card) {
+	e.str(card.ID)
+	e.str(string(card.Type))
+	e.str(card.Name)
+	e.str(string(card.Archetype))
+	e.int(int64(card.Attack))
+	e.int(int64(card.Defense))
+	e.int(int64(card.Cost))
+	e.str(card.Effect)
+	e.uint(uint64(len(card.Effects)))
+	for _, effect := range card.Effects {
+		e.effect(effect)
+	}
+	e.uint(uint64(len(card.Triggers)))
+	for _, trigger := range card.Triggers {
+		e.str(string(trigger.On))
+		e.str(string(trigger.Scope))
+		e.effect(trigger.Effect)
+	}
+	e.keywords(card.Keywords)
+}
+
+func (e *encoder) keywords(keywords []Keyword) {
+	e.uint(uint64(len(keywords)))
+	for _, keyword := range keywords {
+		e.str(string(keyword))
+	}
+}
+
+func (e *encoder) effect(effect CardEffect) {
+	e.str(string(effect.Kind))
+	e.int(int64(effect.Amount))
+	e.str(string(effect.Target))
+	e.flags(effect.Condition != nil, effect.Modifier != nil)
+	if effect.Condition != nil {
+		e.str(string(effect.Condition.Kind))
+		e.int(int64(effect.Condition.Value))
+		e.str(string(effect.Condition.Archetype))
+	}
+	if effect.Modifier != nil {
+		e.modifier(*effect.Modifier)
+	}
+}
+
+func (e *encoder) modifier(modifier Modifier) {
+	e.str(string(modifier.Kind))
+	e.int(int64(modifier.Attack))
+	e.int(int64(modifier.Defense))
+	e.int(int64(modifier.Amount))
+	e.int(int64(modifier.Turns))
+}
+
+func (e *encoder) rules(rules RuleSet) {
+	e.str(rules.Name)
+	e.int(int64(rules.StartingHP))
+	e.int(int64(rules.StartingMana))
+	e.int(int64(rules.StartingMaxMana))
+	e.int(int64(rules.ManaPerTurn))
+	e.int(int64(rules.MaxMana))
+	e.int(int64(rules.OpeningHand))
+	e.int(int64(rules.MaxHandSize))
+	e.int(int64(rules.MaxFieldSize))
+	e.int(int64(rules.DeckSize))
+	e.flags(rules.Mulligan, rules.SummoningSickness)
+	e.str(string(rules.DeckOut))
+	e.int(int64(rules.FatigueDamage))
+	e.int(int64(rules.ArchetypeBonusPercent))
+	e.int(int64(rules.TurnTime))
+	e.int(int64(rules.MatchTime))
+	e.int(int64(rules.MaxTimeouts))
+	e.int(int64(rules.ResponseTime))
+}
+
+func (e *encoder) result(result *MatchResult) {
+	e.ref(result.Winner)
+	e.ref(result.Loser)
+	e.flags(result.Draw)
+	e.str(string(result.Reason))
+	e.int(int64(result.Turn))
+}
+
+func (e *encoder) stack(stack []StackItem) {
+	e.uint(uint64(len(stack)))
+	for _, item := range stack {
+		e.ref(item.PlayerID)
+		e.action(item.Action)
+		e.card(item.Card)
+		e.event(item.Event)
+	}
+}
+
+func (e *encoder) event(event Event) {
+	e.str(string(event.Type))
+	e.ref(event.PlayerID)
+	e.str(event.CardID)
+	e.str(event.InstanceID)
+	e.str(event.CardName)
+	e.int(int64(event.Amount))
+}
+
+// action writes the fields of an action after a bitfield of which are set
+func (e *encoder) action(a Action) {
+	e.str(string(a.Type))
+	e.flags(a.CardID != "", a.AttackerID != "", a.TargetID != "", a.Phase != "", len(a.CardIDs) > 0, a.Reason != "")
+	for _, field := range []string{a.CardID, a.AttackerID, a.TargetID, string(a.Phase)} {
+		if field != "" {
+			e.str(field)
+		}
+	}
+	if len(a.CardIDs) > 0 {
+		e.uint(uint64(len(a.CardIDs)))
+		for _, cardID := range a.CardIDs {
+			e.str(cardID)
+		}
+	}
+	if a.Reason != "" {
+		e.str(string(a.Reason))
+	}
+}
+
+// lookup returns the catalog definition a card was made from, or the
+// card's own definition when the catalog does not have it
+func (c *Codec) lookup(card Card) (Card, bool) {
+	if i, exists := c.index[card.ID]; exists && definitionKey(c.defs[i]) == definitionKey(card) {
+		return c.defs[i], true
+	}
+	return card.definition(), false
+}
+
+// decoder reads values back. The first error sticks: every later read
+// returns a zero value and finish reports it.
+type decoder struct {
+	data    []byte
+	codec   *Codec
+	err     error
+	player1 string
+	player2 string
+}
+
+// readHeader checks the magic, version and kind of an encoded value
+func readHeader(data []byte, kind byte) (*decoder, error) {
+	if len(data) < len(codecMagic)+2 || !bytes.HasPrefix(data, []byte(codecMagic)) {
+		return nil, fmt.Errorf("not an encoded game value")
+	}
+	data = data[len(codecMagic):]
+	if data[0] != CodecVersion {
+		return nil, fmt.Errorf("unsupported codec version %d", data[0])
+	}
+	if data[1] != kind {
+		return nil, fmt.Errorf("encoded value is of kind %q, not %q", data[1], kind)
+	}
+	return &decoder{data: data[2:]}, nil
+}
+
+func (c *Codec) newDecoder(data []byte, kind byte) (*decoder, error) {
+	d, err := readHeader(data, kind)
+	if err != nil {
+		return nil, err
+	}
+	if len(d.data) < len(c.fingerprint) || !bytes.Equal(d.data[:len(c.fingerprint)], c.fingerprint) {
+		return nil, fmt.Errorf("encoded with a different card catalog")
+	}
+	d.data = d.data[len(c.fingerprint):]
+	d.codec = c
+	return d, nil
+}
+
+// finish reports the first error, or an error if data was left over
+func (d *decoder) finish() error {
+	if d.err == nil && len(d.data) > 0 {
+		d.err = fmt.Errorf("%d bytes of trailing data", len(d.data))
+	}
+	return d.err
+}
+
+func (d *decoder) fail(err error) {
+	if d.err == nil {
+		d.err = err
+		d.data = nil
+	}
+}
+
+func (d *decoder) players(player1, player2 string) {
+	d.player1 = player1
+	d.player2 = player2
+}
+
+func (d *decoder) uint() uint64 {
+	n, size := binary.Uvarint(d.data)
+	if size <= 0 {
+		d.fail(fmt.Errorf("truncated or corrupt data"))
+		return 0
+	}
+	d.data = d.data[size:]
+	return n
+}
+
+func (d *decoder) int() int64 {
+	n, size := binary.Varint(d.data)
+	if size <= 0 {
+		d.fail(fmt.Errorf("truncated or corrupt data"))
+		return 0
+	}
+	d.data = d.data[size:]
+	return n
+}
+
+// count reads a list length, refusing lengths the remaining data cannot hold
+func (d *decoder) count() int {
+	n := d.uint()
+	if n > uint64(len(d.data)) {
+		d.fail(fmt.Errorf("truncated or corrupt data"))
+		return 0
+	}
+	return int(n)
+}
+
+func (d *decoder) str() string {
+	n := d.count()
+	s := string(d.data[:n])
+	d.data = d.data[n:]
+	return s
+}
+
+func (d *decoder) time() time.Time {
+	if d.uint()&(1<<0) == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, d.int())
+}
+
+func (d *decoder) ref() string {
+	switch d.uint() {
+	case 0:
+		return ""
+	case 1:
+		return d.player1
+	case 2:
+		return d.player2
+	}
+	return d.str()
+}
+
+func (d *decoder) player(id string) *Player {
+	p := &Player{ID: id, Name: d.str()}
+	p.HP = int(d.int())
+	p.Mana = int(d.int())
+	p.MaxMana = int(d.int())
+	p.Deck = d.cards()
+	p.Hand = d.cards()
+	p.Field = d.cards()
+	p.Graveyard = d.cards()
+	p.Traps = d.cards()
+	p.ArchetypeBonus = d.bonus()
+	p.Fatigue = int(d.int())
+	p.MulliganDone = d.uint()&(1<<0) != 0
+	p.TimeBank = time.Duration(d.int())
+	p.Timeouts = int(d.int())
+	return p
+}
+
+func (d *decoder) playerView(id string) *PlayerView {
+	p := &PlayerView{ID: id, Name: d.str()}
+	p.HP = int(d.int())
+	p.Mana = int(d.int())
+	p.MaxMana = int(d.int())
+	p.Hand = d.cards()
+	p.HandCount = int(d.int())
+	p.DeckCount = int(d.int())
+	p.Field = d.cards()
+	p.Graveyard = d.cards()
+	p.Traps = d.cards()
+	p.TrapCount = int(d.int())
+	p.ArchetypeBonus = d.bonus()
+	p.Fatigue = int(d.int())
+	p.MulliganDone = d.uint()&(1<<0) != 0
+	p.TimeBank = time.Duration(d.int())
+	p.Timeouts = int(d.int())
+	return p
+}
+
+func (d *decoder) bonus() map[Archetype]float32 {
+	n := d.count()
+	bonus := make(map[Archetype]float32, n)
+	for i := 0; i < n; i++ {
+		archetype := Archetype(d.str())
+		if len(d.data) < 4 {
+			d.fail(fmt.Errorf("truncated or corrupt data"))
+			break
+		}
+		bonus[archetype] = math.Float32frombits(binary.LittleEndian.Uint32(d.data))
+		d.data = d.data[4:]
+	}
+	return bonus
+}
+
+func (d *decoder) cards() []Card {
+	cards := make([]Card, d.count())
+	for i := range cards {
+		cards[i] = d.card()
+	}
+	return cards
+}
+
+func (d *decoder) card() Card {
+	flags := d.uint()
+
+	var card Card
+	var printed []Keyword
+	if flags&cardInline != 0 {
+		card = d.definition()
+	} else {
+		i := d.uint()
+		if i >= uint64(len(d.codec.defs)) {
+			d.fail(fmt.Errorf("unknown card definition %d", i))
+			return Card{}
+		}
+		card = d.codec.defs[i].clone()
+	}
+	printed = card.Keywords
+
+	if flags&cardStats != 0 {
+		card.Attack = int(d.int())
+		card.Defense = int(d.int())
+	}
+	if flags&cardKeywords != 0 {
+		card.Keywords = d.keywords()
+	}
+	if flags&cardInstance != 0 {
+		card.InstanceID = d.str()
+	}
+	card.HasAttacked = flags&cardAttacked != 0
+	if flags&cardEntered != 0 {
+		card.EnteredTurn = int(d.int())
+	}
+	if flags&cardBase != 0 {
+		card.BaseAttack = int(d.int())
+		card.BaseDefense = int(d.int())
+	}
+	if flags&cardDamage != 0 {
+		card.Damage = int(d.int())
+	}
+	if flags&cardModifiers != 0 {
+		card.Modifiers = make([]Modifier, d.count())
+		for i := range card.Modifiers {
+			card.Modifiers[i] = d.modifier()
+		}
+	}
+	if flags&cardAttached != 0 {
+		card.Attached = d.cards()
+	}
+	if flags&cardPrintedKeywords != 0 {
+		card.BaseKeywords = append([]Keyword(nil), printed...)
+	}
+	if flags&cardBaseKeywords != 0 {
+		card.BaseKeywords = d.keywords()
+	}
+	return card
+}
+
+func (d *decoder) definition() Card {
+	card := Card{
+		ID:        d.str(),
+		Type:      CardType(d.str()),
+		Name:      d.str(),
+		Archetype: Archetype(d.str()),
+		Attack:    int(d.int()),
+		Defense:   int(d.int()),
+		Cost:      int(d.int()),
+		Effect:    d.str(),
+	}
+	if n := d.count(); n > 0 {
+		card.Effects = make([]CardEffect, n)
+		for i := range card.Effects {
+			card.Effects[i] = d.effect()
+		}
+	}
+	if n := d.count(); n > 0 {
+		card.Triggers = make([]TriggeredAbility, n)
+		for i := range card.Triggers {
+			card.Triggers[i] = TriggeredAbility{On: EventType(d.str()), Scope: TriggerScope(d.str()), Effect: d.effect()}
+		}
+	}
+	card.Keywords = d.keywords()
+	return card
+}
+
+func (d *decoder) keywords() []Keyword {
+	n := d.count()
+	if n == 0 {
+		return nil
+	}
+	keywords := make([]Keyword, n)
+	for i := range keywords {
+		keywords[i] = Keyword(d.str())
+	}
+	return keywords
+}
+
+func (d *decoder) effect() CardEffect {
+	effect := CardEffect{
+		Kind:   EffectKind(d.str()),
+		Amount: int(d.int()),
+		Target: EffectTarget(d.str()),
+	}
+	flags := d.uint()
+	if flags&(1<<0) != 0 {
+		effect.Condition = &EffectCondition{
+			Kind:      ConditionKind(d.str()),
+			Value:     int(d.int()),
+			Archetype: Archetype(d.str()),
+		}
+	}
+	if flags&(1<<1) != 0 {
+		modifier := d.modifier()
+		effect.Modifier = &modifier
+	}
+	return effect
+}
+
+func (d *decoder) modifier() Modifier {
+	return Modifier{
+		Kind:    ModifierKind(d.str()),
+		Attack:  int(d.int()),
+		Defense: int(d.int()),
+		Amount:  int(d.int()),
+		Turns:   int(d.int()),
+	}
+}
+
+func (d *decoder) rules() RuleSet {
+	rules := RuleSet{
+		Name:            d.str(),
+		StartingHP:      int(d.int()),
+		StartingMana:    int(d.int()),
+		StartingMaxMana: int(d.int()),
+		ManaPerTurn:     int(d.int()),
+		MaxMana:         int(d.int()),
+		OpeningHand:     int(d.int()),
+		MaxHandSize:     int(d.int()),
+		MaxFieldSize:    int(d.int()),
+		DeckSize:        int(d.int()),
+	}
+	flags := d.uint()
+	rules.Mulligan = flags&(1<<0) != 0
+	rules.SummoningSickness = flags&(1<<1) != 0
+	rules.DeckOut = DeckOutRule(d.str())
+	rules.FatigueDamage = int(d.int())
+	rules.ArchetypeBonusPercent = int(d.int())
+	rules.TurnTime = time.Duration(d.int())
+	rules.MatchTime = time.Duration(d.int())
+	rules.MaxTimeouts = int(d.int())
+	rules.ResponseTime = time.Duration(d.int())
+	return rules
+}
+
+func (d *decoder) result() *MatchResult {
+	return &MatchResult{
+		Winner: d.ref(),
+		Loser:  d.ref(),
+		Draw:   d.uint()&(1<<0) != 0,
+		Reason: EndReason(d.str()),
+		Turn:   int(d.int()),
+	}
+}
+
+func (d *decoder) stack() []StackItem {
+	n := d.count()
+	if n == 0 {
+		return nil
+	}
+	stack := make([]StackItem, n)
+	for i := range stack {
+		stack[i] = StackItem{PlayerID: d.ref(), Action: d.action(), Card: d.card(), Event: d.event()}
+	}
+	return stack
+}
+
+func (d *decoder) event() Event {
+	return Event{
+		Type:       EventType(d.str()),
+		PlayerID:   d.ref(),
+		CardID:     d.str(),
+		InstanceID: d.str(),
+		CardName:   d.str(),
+		Amount:     int(d.int()),
+	}
+}
+
+func (d *decoder) action() Action {
+	a := Action{Type: ActionType(d.str())}
+	flags := d.uint()
+	fields := []*string{&a.CardID, &a.AttackerID, &a.TargetID}
+	for i, field := range fields {
+		if flags&(1<<i) != 0 {
+			*field = d.str()
+		}
+	}
+	if flags&(1<<3) != 0 {
+		a.Phase = GamePhase(d.str())
+	}
+	if flags&(1<<4) != 0 {
+		a.CardIDs = make([]string, d.count())
+		for i := range a.CardIDs {
+			a.CardIDs[i] = d.str()
+		}
+	}
+	if flags&(1<<5) != 0 {
+		a.Reason = EndReason(d.str())
+	}
+	return a
+}
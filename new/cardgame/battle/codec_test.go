@@ -0,0 +1,187 @@
+package battle_test
+
+import (
+	"encoding/json"
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+
+	"cardgame/battle"
+	"cardgame/game"
+)
+
+func newCodec(t *testing.T) *battle.Codec {
+	t.Helper()
+	decks := game.NewDeckBuilder()
+	codec, err := battle.NewCodec(append(decks.CreateEgyptianDeck(), decks.CreateGreekDeck()...))
+	if err != nil {
+		t.Fatalf("building codec: %v", err)
+	}
+	return codec
+}
+
+func TestCodecStateRoundTrip(t *testing.T) {
+	withCatalog := newCodec(t)
+	inline, _ := battle.NewCodec(nil)
+
+	for seed := int64(1); seed <= 3; seed++ {
+		be := battle.NewBattleEngine()
+		g := newMatch(t, be, seed)
+		playRandom(t, be, g.ID, rand.New(rand.NewSource(seed)), 300, func(s *battle.GameState) {
+			for _, codec := range []*battle.Codec{withCatalog, inline} {
+				decoded, err := codec.DecodeState(codec.EncodeState(s))
+				if err != nil {
+					t.Fatalf("seed %d version %d: %v", seed, s.Version, err)
+				}
+				if decoded.Hash() != s.Hash() {
+					t.Fatalf("seed %d version %d: decoded state hashes differently", seed, s.Version)
+				}
+			}
+		})
+	}
+}
+
+func TestCodecUpdateRoundTrip(t *testing.T) {
+	codec := newCodec(t)
+	be := battle.NewBattleEngine()
+	g := newMatch(t, be, 7)
+	playRandom(t, be, g.ID, rand.New(rand.NewSource(7)), 200, func(s *battle.GameState) {
+		for _, viewer := range []string{"A", "B", ""} {
+			view := s.ViewFor(viewer)
+			legal, _ := be.LegalActions(g.ID, viewer)
+			update, err := codec.DecodeUpdate(codec.EncodeUpdate(view, legal))
+			if err != nil {
+				t.Fatalf("version %d: %v", s.Version, err)
+			}
+			if update.View.Hash() != view.Hash() || update.ViewHash != view.Hash() {
+				t.Fatalf("version %d: decoded view hashes differently", s.Version)
+			}
+			if len(update.Legal) != len(legal) {
+				t.Fatalf("version %d: got %d legal actions, want %d", s.Version, len(update.Legal), len(legal))
+			}
+			for i := range legal {
+				if !update.Legal[i].Equal(legal[i]) {
+					t.Fatalf("version %d: legal action %d decoded as %v, want %v", s.Version, i, update.Legal[i], legal[i])
+				}
+			}
+		}
+	})
+}
+
+func TestCodecRecordRoundTrip(t *testing.T) {
+	codec := newCodec(t)
+	be := battle.NewBattleEngine()
+	g := newMatch(t, be, 11)
+	final := playRandom(t, be, g.ID, rand.New(rand.NewSource(11)), 400, nil)
+	if !final.GameOver {
+		if _, err := be.Forfeit(g.ID, "A", battle.ReasonConcession); err != nil {
+			t.Fatalf("forfeiting: %v", err)
+		}
+	}
+	record, err := be.CloseMatch(g.ID)
+	if err != nil {
+		t.Fatalf("closing match: %v", err)
+	}
+
+	decoded, err := codec.DecodeRecord(codec.EncodeRecord(record))
+	if err != nil {
+		t.Fatalf("decoding record: %v", err)
+	}
+	want, _ := json.Marshal(record)
+	got, _ := json.Marshal(decoded)
+	if string(got) != string(want) {
+		t.Fatalf("decoded record differs:\ngot  %s\nwant %s", got, want)
+	}
+
+	original, err := battle.Replay(record.Setup, record.Log, -1)
+	if err != nil {
+		t.Fatalf("replaying original: %v", err)
+	}
+	replayed, err := battle.Replay(decoded.Setup, decoded.Log, -1)
+	if err != nil {
+		t.Fatalf("replaying decoded: %v", err)
+	}
+	if replayed.Hash() != original.Hash() {
+		t.Fatalf("decoded record replays to a different game")
+	}
+}
+
+func TestCodecActionRoundTrip(t *testing.T) {
+	actions := []battle.Action{
+		{Type: battle.ActionEndTurn},
+		{Type: battle.ActionPlayCard, CardID: "p1-3", TargetID: "p2-7"},
+		{Type: battle.ActionAttack, AttackerID: "p1-1"},
+		{Type: battle.ActionChangePhase, Phase: battle.PhaseBattle},
+		{Type: battle.ActionMulligan, CardIDs: []string{"p2-1", "p2-4"}},
+		{Type: battle.ActionConcede, Reason: battle.ReasonConcession},
+	}
+	for _, action := range actions {
+		decoded, err := battle.DecodeAction(battle.EncodeAction(action))
+		if err != nil {
+			t.Fatalf("%v: %v", action, err)
+		}
+		if !reflect.DeepEqual(decoded, action) {
+			t.Fatalf("decoded %#v, want %#v", decoded, action)
+		}
+	}
+}
+
+func TestCodecRejectsBadInput(t *testing.T) {
+	codec := newCodec(t)
+	be := battle.NewBattleEngine()
+	g := newMatch(t, be, 3)
+	state := codec.EncodeState(playRandom(t, be, g.ID, rand.New(rand.NewSource(3)), 50, nil))
+
+	modified := func(change func([]byte) []byte) []byte {
+		return change(append([]byte(nil), state...))
+	}
+	other, _ := battle.NewCodec(nil)
+
+	tests := []struct {
+		name   string
+		decode func() error
+		want   string
+	}{
+		{"unknown version", func() error {
+			_, err := codec.DecodeState(modified(func(b []byte) []byte { b[2] = battle.CodecVersion + 1; return b }))
+			return err
+		}, "unsupported codec version"},
+		{"wrong kind", func() error {
+			_, err := codec.DecodeRecord(state)
+			return err
+		}, "kind"},
+		{"action as state", func() error {
+			_, err := codec.DecodeState(battle.EncodeAction(battle.Action{Type: battle.ActionEndTurn}))
+			return err
+		}, "kind"},
+		{"different catalog", func() error {
+			_, err := other.DecodeState(state)
+			return err
+		}, "different card catalog"},
+		{"trailing bytes", func() error {
+			_, err := codec.DecodeState(modified(func(b []byte) []byte { return append(b, 0) }))
+			return err
+		}, "trailing data"},
+		{"not encoded", func() error {
+			_, err := codec.DecodeState([]byte("{}"))
+			return err
+		}, "not an encoded game value"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.decode()
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("got error %v, want one mentioning %q", err, tt.want)
+			}
+		})
+	}
+
+	t.Run("truncated", func(t *testing.T) {
+		for n := 0; n < len(state); n++ {
+			if _, err := codec.DecodeState(state[:n]); err == nil {
+				t.Fatalf("state cut to %d of %d bytes decoded", n, len(state))
+			}
+		}
+	})
+}
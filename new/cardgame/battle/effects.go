@@ -0,0 +1,265 @@
+package battle
+
+import "fmt"
+
+// EffectKind identifies what an effect does when it resolves
+type EffectKind string
+
+const (
+	EffectDraw   EffectKind = "draw"
+	EffectDamage EffectKind = "damage"
+	EffectHeal   EffectKind = "heal"
+	EffectMana   EffectKind = "mana"
+	// EffectCounter removes the move a response was played against from
+	// the stack without letting it resolve
+	EffectCounter EffectKind = "counter"
+	// EffectModifier puts the effect's Modifier on every field card it targets
+	EffectModifier EffectKind = "modifier"
+)
+
+// EffectTarget selects who or what an effect is applied to
+type EffectTarget string
+
+const (
+	// TargetSelf is the player who controls the card
+	TargetSelf EffectTarget = "self"
+	// TargetOpponent is the opposing player
+	TargetOpponent EffectTarget = "opponent"
+	// TargetEnemyField is every card on the opponent's field
+	TargetEnemyField EffectTarget = "enemy_field"
+	// TargetAllEnemies is the opponent and every card on their field
+	TargetAllEnemies EffectTarget = "all_enemies"
+	// TargetRandomEnemyCard is one random card on the opponent's field
+	TargetRandomEnemyCard EffectTarget = "random_enemy_card"
+	// TargetTriggeringCard is the enemy card named by the event that fired a
+	// triggered ability, such as the attacker of an attack_declared event
+	TargetTriggeringCard EffectTarget = "triggering_card"
+	// TargetOwnField is every card on the controller's field
+	TargetOwnField EffectTarget = "own_field"
+)
+
+// ConditionKind identifies a check made before an effect resolves
+type ConditionKind string
+
+const (
+	// ConditionOpponentHasField requires at least Value cards on the opponent's field
+	ConditionOpponentHasField ConditionKind = "opponent_has_field"
+	// ConditionOwnHPBelow requires the controller's HP to be below Value
+	ConditionOwnHPBelow ConditionKind = "own_hp_below"
+	// ConditionArchetypeOnField requires at least Value other cards of Archetype on the controller's field
+	ConditionArchetypeOnField ConditionKind = "archetype_on_field"
+)
+
+// EffectCondition gates an effect on the current board
+type EffectCondition struct {
+	Kind      ConditionKind `json:"kind"`
+	Value     int           `json:"value,omitempty"`
+	Archetype Archetype     `json:"archetype,omitempty"`
+}
+
+// CardEffect describes a single effect printed on a card
+type CardEffect struct {
+	Kind      EffectKind       `json:"kind"`
+	Amount    int              `json:"amount"`
+	Target    EffectTarget     `json:"target"`
+	Condition *EffectCondition `json:"condition,omitempty"`
+	Modifier  *Modifier        `json:"modifier,omitempty"`
+}
+
+// String returns a short human readable form of the effect
+func (e CardEffect) String() string {
+	return fmt.Sprintf("%s %d (%s)", e.Kind, e.Amount, e.Target)
+}
+
+// applyCardEffect resolves every effect printed on a card in order
+func (be *BattleEngine) applyCardEffect(game *GameState, player *Player, card Card) {
+	for _, effect := range card.Effects {
+		be.resolveEffect(game, player, effect, nil)
+	}
+}
+
+// resolveEffect applies a single effect on behalf of player. trigger is the
+// event that fired the effect, or nil for effects of a played card.
+func (be *BattleEngine) resolveEffect(game *GameState, player *Player, effect CardEffect, trigger *Event) {
+	opponent := be.getOpponent(game, player.ID)
+
+	if !be.checkCondition(player, opponent, effect.Condition) {
+		return
+	}
+
+	switch effect.Kind {
+	case EffectDraw:
+		for _, target := range be.selectPlayers(player, opponent, effect.Target) {
+			be.draw(game, target, effect.Amount)
+		}
+	case EffectMana:
+		for _, target := range be.selectPlayers(player, opponent, effect.Target) {
+			target.Mana += effect.Amount
+		}
+	case EffectHeal:
+		for _, target := range be.selectPlayers(player, opponent, effect.Target) {
+			be.healPlayer(game, target, effect.Amount)
+		}
+	case EffectDamage:
+		for _, target := range be.selectPlayers(player, opponent, effect.Target) {
+			be.damagePlayer(game, target, effect.Amount)
+		}
+		be.damageFieldCards(game, opponent, effect, trigger)
+	case EffectModifier:
+		be.modifyFieldCards(game, player, opponent, effect, trigger)
+	}
+}
+
+// modifyFieldCards puts the effect's modifier on the field cards it targets.
+// Debuffs that leave a card without defense destroy it.
+func (be *BattleEngine) modifyFieldCards(game *GameState, player, opponent *Player, effect CardEffect, trigger *Event) {
+	if effect.Modifier == nil {
+		return
+	}
+
+	if effect.Target == TargetOwnField {
+		for i := range player.Field {
+			be.applyModifier(game, &player.Field[i], *effect.Modifier)
+		}
+		return
+	}
+
+	for _, index := range be.selectFieldCards(game, opponent, effect.Target, trigger) {
+		be.applyModifier(game, &opponent.Field[index], *effect.Modifier)
+	}
+	be.removeDestroyed(game, opponent)
+}
+
+// checkCondition reports whether an effect's condition currently holds
+func (be *BattleEngine) checkCondition(player, opponent *Player, cond *EffectCondition) bool {
+	if cond == nil {
+		return true
+	}
+
+	switch cond.Kind {
+	case ConditionOpponentHasField:
+		return len(opponent.Field) >= max(cond.Value, 1)
+	case ConditionOwnHPBelow:
+		return player.HP < cond.Value
+	case ConditionArchetypeOnField:
+		// The card that carries the effect is already on the field
+		return countArchetype(player, cond.Archetype)-1 >= cond.Value
+	}
+
+	return false
+}
+
+// selectPlayers returns the players an effect applies to
+func (be *BattleEngine) selectPlayers(player, opponent *Player, target EffectTarget) []*Player {
+	switch target {
+	case TargetSelf:
+		return []*Player{player}
+	case TargetOpponent, TargetAllEnemies:
+		return []*Player{opponent}
+	}
+	return nil
+}
+
+// damageFieldCards applies damage to the opponent's field cards selected by the effect.
+// Damage lowers a card's defense and destroys it once defense reaches zero.
+func (be *BattleEngine) damageFieldCards(game *GameState, opponent *Player, effect CardEffect, trigger *Event) {
+	targets := be.selectFieldCards(game, opponent, effect.Target, trigger)
+	for _, index := range targets {
+		be.damageCard(opponent, index, effect.Amount)
+	}
+	if len(targets) > 0 {
+		be.removeDestroyed(game, opponent)
+	}
+}
+
+// selectFieldCards returns the indices of the opponent's field cards an
+// effect target selects
+func (be *BattleEngine) selectFieldCards(game *GameState, opponent *Player, target EffectTarget, trigger *Event) []int {
+	var targets []int
+
+	switch target {
+	case TargetEnemyField, TargetAllEnemies:
+		for i := range opponent.Field {
+			targets = append(targets, i)
+		}
+	case TargetRandomEnemyCard:
+		if len(opponent.Field) > 0 {
+			targets = append(targets, game.rng.Intn(len(opponent.Field)))
+		}
+	case TargetTriggeringCard:
+		if trigger != nil && trigger.PlayerID == opponent.ID {
+			if index := findCard(opponent.Field, trigger.InstanceID); index != -1 {
+				targets = append(targets, index)
+			}
+		}
+	}
+
+	return targets
+}
+
+// damageCard deals damage to a field card unless a shield absorbs it.
+// The card is not removed here even if its defense reaches zero.
+func (be *BattleEngine) damageCard(player *Player, index int, amount int) {
+	card := &player.Field[index]
+	if card.consumeShield() {
+		return
+	}
+	card.Damage += amount
+	card.Defense -= amount
+}
+
+// removeDestroyed recomputes the board and moves every field card of player
+// left without defense to the graveyard. All of them are removed before any
+// destruction triggers resolve, so abilities reacting to one never observe
+// shifted field indices.
+func (be *BattleEngine) removeDestroyed(game *GameState, player *Player) {
+	be.recomputeStats(game)
+
+	var destroyed []Card
+	for i := len(player.Field) - 1; i >= 0; i-- {
+		if player.Field[i].Defense <= 0 {
+			destroyed = append(destroyed, removeFieldCard(player, i))
+		}
+	}
+	if len(destroyed) == 0 {
+		return
+	}
+
+	// Losing allies weakens archetype auras
+	be.recomputeStats(game)
+	for _, card := range destroyed {
+		be.emit(game, Event{Type: EventCardDestroyed, PlayerID: player.ID, CardID: card.ID, InstanceID: card.InstanceID, CardName: card.Name})
+	}
+}
+
+// damagePlayer deals damage to a player's HP
+func (be *BattleEngine) damagePlayer(game *GameState, player *Player, amount int) {
+	player.HP -= amount
+	be.emit(game, Event{Type: EventPlayerDamaged, PlayerID: player.ID, Amount: amount})
+}
+
+// healPlayer restores a player's HP up to the starting HP
+func (be *BattleEngine) healPlayer(game *GameState, player *Player, amount int) {
+	player.HP = min(player.HP+amount, game.Rules.StartingHP)
+}
+
+// destroyCard moves a field card to its owner's graveyard
+func (be *BattleEngine) destroyCard(game *GameState, player *Player, index int) Card {
+	card := removeFieldCard(player, index)
+	be.recomputeStats(game)
+	be.emit(game, Event{Type: EventCardDestroyed, PlayerID: player.ID, CardID: card.ID, InstanceID: card.InstanceID, CardName: card.Name})
+	return card
+}
+
+// removeFieldCard moves a field card and any equipment attached to it to
+// the graveyard without emitting any event
+func removeFieldCard(player *Player, index int) Card {
+	card := player.Field[index]
+	player.Field = append(player.Field[:index], player.Field[index+1:]...)
+
+	attached := card.Attached
+	card.leaveField()
+	player.Graveyard = append(player.Graveyard, card)
+	player.Graveyard = append(player.Graveyard, attached...)
+	return card
+}
@@ -2,34 +2,44 @@ package battle
 
 import (
 	//"encoding/json"
+	"context"
 	"fmt"
 	"math/rand"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
 // Card represents a game card
 type Card struct {
-	ID         string    `json:"id"`
-	Name       string    `json:"name"`
-	Archetype  Archetype `json:"archetype"`
-	Attack     int       `json:"attack"`
-	Defense    int       `json:"defense"`
-	Cost       int       `json:"cost"`
-	Effect     string    `json:"effect"`
-	EffectType string    `json:"effect_type"`
+	ID         string             `json:"id"`
+	InstanceID string             `json:"instance_id,omitempty"`
+	Type       CardType           `json:"type,omitempty"`
+	Name       string             `json:"name"`
+	Archetype  Archetype          `json:"archetype"`
+	Attack     int                `json:"attack"`
+	Defense    int                `json:"defense"`
+	Cost       int                `json:"cost"`
+	Effect     string             `json:"effect"`
+	Effects    []CardEffect       `json:"effects,omitempty"`
+	Triggers   []TriggeredAbility `json:"triggers,omitempty"`
+	Keywords   []Keyword          `json:"keywords,omitempty"`
+
+	// Combat state of a card on the field. Attack and Defense above are the
+	// effective stats, recomputed from the base stats whenever the board changes.
+	HasAttacked  bool       `json:"has_attacked,omitempty"`
+	EnteredTurn  int        `json:"entered_turn,omitempty"`
+	Attached     []Card     `json:"attached,omitempty"`
+	BaseAttack   int        `json:"base_attack,omitempty"`
+	BaseDefense  int        `json:"base_defense,omitempty"`
+	BaseKeywords []Keyword  `json:"base_keywords,omitempty"`
+	Damage       int        `json:"damage,omitempty"`
+	Modifiers    []Modifier `json:"modifiers,omitempty"`
 }
 
-// Archetype represents card archetypes
-type Archetype string
-
-const (
-	ArchetypeEgyptian Archetype = "egyptian"
-	ArchetypeGreek    Archetype = "greek"
-	ArchetypeNeutral  Archetype = "neutral"
-)
-
-// Player represents a player in the game
+// Player represents a player in the game. ArchetypeBonus is the aura bonus
+// each of the player's field cards of an archetype currently receives, as a
+// fraction of its base stat.
 type Player struct {
 	ID             string                `json:"id"`
 	Name           string                `json:"name"`
@@ -40,333 +50,420 @@ type Player struct {
 	Hand           []Card                `json:"hand"`
 	Field          []Card                `json:"field"`
 	Graveyard      []Card                `json:"graveyard"`
+	Traps          []Card                `json:"traps"`
 	ArchetypeBonus map[Archetype]float32 `json:"archetype_bonus"`
+	Fatigue        int                   `json:"fatigue,omitempty"`
+	MulliganDone   bool                  `json:"mulligan_done,omitempty"`
+	TimeBank       time.Duration         `json:"time_bank,omitempty"`
+	Timeouts       int                   `json:"timeouts,omitempty"`
 }
 
 // GameState represents the current state of the game
 type GameState struct {
-	ID          string    `json:"id"`
-	Player1     *Player   `json:"player1"`
-	Player2     *Player   `json:"player2"`
-	CurrentTurn string    `json:"current_turn"`
-	TurnCount   int       `json:"turn_count"`
-	Phase       GamePhase `json:"phase"`
-	Winner      string    `json:"winner"`
-	GameOver    bool      `json:"game_over"`
-	LastAction  string    `json:"last_action"`
+	ID          string       `json:"id"`
+	Player1     *Player      `json:"player1"`
+	Player2     *Player      `json:"player2"`
+	CurrentTurn string       `json:"current_turn"`
+	TurnCount   int          `json:"turn_count"`
+	Phase       GamePhase    `json:"phase"`
+	Winner      string       `json:"winner"`
+	GameOver    bool         `json:"game_over"`
+	LastAction  string       `json:"last_action"`
+	Version     int          `json:"version"`
+	Seed        int64        `json:"seed"`
+	Rules       RuleSet      `json:"rules"`
+	Result      *MatchResult `json:"result,omitempty"`
+	// Stack holds declared moves waiting to resolve, the last one on top.
+	// Priority is the player who must respond or pass while it is not empty.
+	Stack    []StackItem `json:"stack,omitempty"`
+	Priority string      `json:"priority,omitempty"`
+	// TurnTimeLeft is what remains of the current turn and ClockHolder the
+	// player whose time is running, both as of the last accepted action.
+	// They stay empty when the rules have no time controls.
+	TurnTimeLeft time.Duration `json:"turn_time_left,omitempty"`
+	ClockHolder  string        `json:"clock_holder,omitempty"`
+
+	clockStarted time.Time
+	createdAt    time.Time
+	lastActivity time.Time
+	events       *eventBus
+	rng          *rand.Rand
+	setup        MatchSetup
+	log          []LogEntry
+	subscribers  []*Subscription
 }
 
 // GamePhase represents different phases of a turn
 type GamePhase string
 
 const (
-	PhaseDrawn  GamePhase = "draw"
-	PhaseMain   GamePhase = "main"
-	PhaseBattle GamePhase = "battle"
-	PhaseEnd    GamePhase = "end"
+	// PhaseMulligan opens a match played with mulligans, before the first turn
+	PhaseMulligan GamePhase = "mulligan"
+	PhaseDrawn    GamePhase = "draw"
+	PhaseMain     GamePhase = "main"
+	PhaseBattle   GamePhase = "battle"
+	PhaseMain2    GamePhase = "main2"
+	PhaseEnd      GamePhase = "end"
 )
 
-// BattleEngine manages the game logic
+// BattleEngine manages the game logic. Each live match runs on its own
+// goroutine; mu only guards the set of matches and the archive.
 type BattleEngine struct {
-	games     map[string]*GameState
-	mu        sync.RWMutex
-	callbacks map[string]func(*GameState)
+	games map[string]*match
+	mu    sync.RWMutex
+	clock atomic.Pointer[func() time.Time]
+
+	// ctx is the parent of every match; stop cancels it
+	ctx  context.Context
+	stop context.CancelFunc
+
+	// archive holds closed matches, archiveOrder their IDs oldest first
+	archive      map[string]*MatchRecord
+	archiveOrder []string
+	archiveLimit int
 }
 
 // NewBattleEngine creates a new battle engine instance
 func NewBattleEngine() *BattleEngine {
-	return &BattleEngine{
-		games:     make(map[string]*GameState),
-		callbacks: make(map[string]func(*GameState)),
-	}
+	return NewBattleEngineContext(context.Background())
+}
+
+// NewBattleEngineContext creates a battle engine whose matches all stop
+// once ctx is cancelled
+func NewBattleEngineContext(ctx context.Context) *BattleEngine {
+	ctx, stop := context.WithCancel(ctx)
+	be := &BattleEngine{
+		games:        make(map[string]*match),
+		ctx:          ctx,
+		stop:         stop,
+		archive:      make(map[string]*MatchRecord),
+		archiveLimit: DefaultArchiveLimit,
+	}
+	be.SetClock(time.Now)
+	return be
+}
+
+// CreateMatch creates a new match between two players with a freshly chosen seed
+func (be *BattleEngine) CreateMatch(player1ID, player2ID string, deck1, deck2 []Card, rules RuleSet) (*GameState, error) {
+	return be.CreateSeededMatch(player1ID, player2ID, deck1, deck2, rules, time.Now().UnixNano())
 }
 
-// CreateMatch creates a new match between two players
-func (be *BattleEngine) CreateMatch(player1ID, player2ID string, deck1, deck2 []Card) (*GameState, error) {
+// CreateSeededMatch creates a new match whose every random decision is drawn
+// from seed, so the same seed and moves always reproduce the same game
+func (be *BattleEngine) CreateSeededMatch(player1ID, player2ID string, deck1, deck2 []Card, rules RuleSet, seed int64) (*GameState, error) {
 	be.mu.Lock()
 	defer be.mu.Unlock()
 
-	gameID := fmt.Sprintf("game_%d", time.Now().Unix())
-
-	// Initialize players
-	p1 := &Player{
-		ID:             player1ID,
-		HP:             8000,
-		Mana:           10,
-		MaxMana:        15,
-		Deck:           shuffleDeck(deck1),
-		Hand:           []Card{},
-		Field:          []Card{},
-		Graveyard:      []Card{},
-		ArchetypeBonus: make(map[Archetype]float32),
+	if be.ctx.Err() != nil {
+		return nil, fmt.Errorf("engine is shut down")
 	}
 
-	p2 := &Player{
-		ID:             player2ID,
-		HP:             8000,
-		Mana:           10,
-		MaxMana:        15,
-		Deck:           shuffleDeck(deck2),
-		Hand:           []Card{},
-		Field:          []Card{},
-		Graveyard:      []Card{},
-		ArchetypeBonus: make(map[Archetype]float32),
+	game, err := be.newGame(MatchSetup{
+		GameID:    be.newGameID(),
+		Player1ID: player1ID,
+		Player2ID: player2ID,
+		Deck1:     deck1,
+		Deck2:     deck2,
+		Rules:     rules,
+		Seed:      seed,
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	// Draw initial hands
-	drawCards(p1, 5)
-	drawCards(p2, 5)
+	// Hand out the snapshot before the match goroutine owns the game
+	snapshot := game.Snapshot()
+	be.games[game.ID] = startMatch(be.ctx, game)
+	return snapshot, nil
+}
 
-	game := &GameState{
-		ID:          gameID,
-		Player1:     p1,
-		Player2:     p2,
-		CurrentTurn: player1ID,
-		TurnCount:   1,
-		Phase:       PhaseMain,
-		GameOver:    false,
+// newGame deals a match from its setup, ready for the first action
+func (be *BattleEngine) newGame(setup MatchSetup) (*GameState, error) {
+	rules := setup.Rules
+	if err := rules.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid rules: %v", err)
+	}
+	if err := rules.checkDeck(setup.Deck1); err != nil {
+		return nil, fmt.Errorf("%s: %v", setup.Player1ID, err)
+	}
+	if err := rules.checkDeck(setup.Deck2); err != nil {
+		return nil, fmt.Errorf("%s: %v", setup.Player2ID, err)
 	}
 
-	be.games[gameID] = game
-	be.notifyStateChange(game)
+	setup.Deck1 = copyCards(setup.Deck1)
+	setup.Deck2 = copyCards(setup.Deck2)
+	rng := rand.New(rand.NewSource(setup.Seed))
 
-	return game, nil
-}
+	// Initialize players
+	p1 := newPlayer(setup.Player1ID, shuffleDeck(rng, instantiateDeck("p1", setup.Deck1)), rules)
+	p2 := newPlayer(setup.Player2ID, shuffleDeck(rng, instantiateDeck("p2", setup.Deck2)), rules)
 
-// DrawCard handles drawing a card for the current player
-func (be *BattleEngine) DrawCard(gameID, playerID string) error {
-	be.mu.Lock()
-	defer be.mu.Unlock()
+	// Draw initial hands
+	drawCards(rules, p1, rules.OpeningHand)
+	drawCards(rules, p2, rules.OpeningHand)
 
-	game, exists := be.games[gameID]
-	if !exists {
-		return fmt.Errorf("game not found")
+	// The first turn waits until both players have kept or redrawn
+	phase, turnTime := PhaseMain, rules.TurnTime
+	if rules.Mulligan {
+		phase, turnTime = PhaseMulligan, rules.mulliganTime()
 	}
 
-	if game.CurrentTurn != playerID {
-		return fmt.Errorf("not your turn")
-	}
+	game := &GameState{
+		ID:           setup.GameID,
+		Player1:      p1,
+		Player2:      p2,
+		CurrentTurn:  setup.Player1ID,
+		TurnCount:    1,
+		Phase:        phase,
+		GameOver:     false,
+		Seed:         setup.Seed,
+		Rules:        rules,
+		TurnTimeLeft: turnTime,
+		events:       newEventBus(),
+		rng:          rng,
+		setup:        setup,
+	}
+
+	game.createdAt = be.now()
+	game.lastActivity = game.createdAt
+	be.restartClock(game)
+	return game, nil
+}
 
+// drawCard handles drawing a card for the current player
+func (be *BattleEngine) drawCard(game *GameState, player *Player) error {
 	if game.Phase != PhaseDrawn {
 		return fmt.Errorf("can only draw during draw phase")
 	}
 
-	player := be.getPlayer(game, playerID)
-	if player == nil {
-		return fmt.Errorf("player not found")
+	if !be.draw(game, player, 1) {
+		game.LastAction = fmt.Sprintf("%s could not draw from an empty deck", player.ID)
+	} else {
+		game.LastAction = fmt.Sprintf("%s drew a card", player.ID)
 	}
 
-	if drawCards(player, 1) {
+	if !game.GameOver {
 		game.Phase = PhaseMain
-		be.notifyStateChange(game)
 	}
-
 	return nil
 }
 
-// PlayCard plays a card from hand to field
-func (be *BattleEngine) PlayCard(gameID, playerID string, cardIndex int) error {
-	be.mu.Lock()
-	defer be.mu.Unlock()
-
-	game, exists := be.games[gameID]
-	if !exists {
-		return fmt.Errorf("game not found")
+// draw draws count cards for player, applying the deck-out rule for every
+// card the deck cannot supply. It reports whether all cards were drawn.
+func (be *BattleEngine) draw(game *GameState, player *Player, count int) bool {
+	drewAll := true
+	for i := 0; i < count && !game.GameOver; i++ {
+		if !drawCards(game.Rules, player, 1) {
+			be.deckOut(game, player)
+			drewAll = false
+		}
 	}
+	return drewAll
+}
 
-	if game.CurrentTurn != playerID {
-		return fmt.Errorf("not your turn")
+// checkPlayCard reports why a card in hand cannot be played, if it cannot.
+// targetID names the creature an equipment card attaches to.
+func (be *BattleEngine) checkPlayCard(game *GameState, player *Player, cardID, targetID string) error {
+	if !game.Phase.IsMain() {
+		return fmt.Errorf("can only play cards during a main phase")
 	}
 
-	if game.Phase != PhaseMain {
-		return fmt.Errorf("can only play cards during main phase")
+	cardIndex := findCard(player.Hand, cardID)
+	if cardIndex == -1 {
+		return fmt.Errorf("card not in hand")
 	}
 
-	player := be.getPlayer(game, playerID)
-	if player == nil {
-		return fmt.Errorf("player not found")
+	// Check mana cost
+	if player.Hand[cardIndex].Cost > player.Mana {
+		return fmt.Errorf("insufficient mana")
 	}
 
-	if cardIndex < 0 || cardIndex >= len(player.Hand) {
-		return fmt.Errorf("invalid card index")
+	return be.checkCardType(game, player, player.Hand[cardIndex], targetID)
+}
+
+// playCard pays for a card and takes it from hand. Traps are set at once;
+// any other card goes on the stack, where the opponent may respond to it
+// before it resolves.
+func (be *BattleEngine) playCard(game *GameState, player *Player, cardID, targetID string) error {
+	if err := be.checkPlayCard(game, player, cardID, targetID); err != nil {
+		return err
 	}
 
+	cardIndex := findCard(player.Hand, cardID)
 	card := player.Hand[cardIndex]
+	player.Hand = append(player.Hand[:cardIndex], player.Hand[cardIndex+1:]...)
+	player.Mana -= card.Cost
 
-	// Check mana cost
-	if card.Cost > player.Mana {
-		return fmt.Errorf("insufficient mana")
+	if card.Type == CardTrap {
+		be.setTrap(game, player, card)
+		return nil
 	}
 
-	// Apply archetype bonuses
-	be.applyArchetypeBonus(player, &card)
+	be.declare(game, StackItem{
+		PlayerID: player.ID,
+		Action:   Action{Type: ActionPlayCard, CardID: cardID, TargetID: targetID},
+		Card:     card,
+		Event:    Event{Type: EventCardPlayed, PlayerID: player.ID, CardID: card.ID, InstanceID: card.InstanceID, CardName: card.Name},
+	})
+	return nil
+}
 
-	// Move card from hand to field
-	player.Hand = append(player.Hand[:cardIndex], player.Hand[cardIndex+1:]...)
+// summon puts a creature that resolved onto its controller's field
+func (be *BattleEngine) summon(game *GameState, player *Player, card Card) {
+	card.enterField()
+	card.HasAttacked = false
+	card.EnteredTurn = game.TurnCount
 	player.Field = append(player.Field, card)
-	player.Mana -= card.Cost
+
+	// Archetype auras change for the whole field
+	be.recomputeStats(game)
 
 	// Apply card effects
 	be.applyCardEffect(game, player, card)
+	be.emit(game, Event{Type: EventCardPlayed, PlayerID: player.ID, CardID: card.ID, InstanceID: card.InstanceID, CardName: card.Name})
 
-	game.LastAction = fmt.Sprintf("%s played %s", playerID, card.Name)
-	be.notifyStateChange(game)
-
-	return nil
+	game.LastAction = fmt.Sprintf("%s played %s", player.ID, card.Name)
 }
 
-// Attack executes an attack with a card
-func (be *BattleEngine) Attack(gameID, playerID string, attackerIndex, targetIndex int) error {
-	be.mu.Lock()
-	defer be.mu.Unlock()
-
-	game, exists := be.games[gameID]
-	if !exists {
-		return fmt.Errorf("game not found")
-	}
-
-	if game.CurrentTurn != playerID {
-		return fmt.Errorf("not your turn")
-	}
-
+// checkAttack reports why an attack cannot be made, if it cannot. An empty
+// targetID is a direct attack.
+func (be *BattleEngine) checkAttack(game *GameState, attacker *Player, attackerID, targetID string) error {
 	if game.Phase != PhaseBattle {
 		return fmt.Errorf("can only attack during battle phase")
 	}
 
-	attacker := be.getPlayer(game, playerID)
-	defender := be.getOpponent(game, playerID)
+	defender := be.getOpponent(game, attacker.ID)
 
-	if attackerIndex < 0 || attackerIndex >= len(attacker.Field) {
-		return fmt.Errorf("invalid attacker index")
+	attackerIndex := findCard(attacker.Field, attackerID)
+	if attackerIndex == -1 {
+		return fmt.Errorf("attacker not on field")
 	}
 
 	attackCard := attacker.Field[attackerIndex]
-
-	// Direct attack to player
-	if targetIndex == -1 {
-		if len(defender.Field) > 0 {
-			return fmt.Errorf("cannot attack directly when opponent has cards")
-		}
-		defender.HP -= attackCard.Attack
-		game.LastAction = fmt.Sprintf("%s attacked directly for %d damage", attackCard.Name, attackCard.Attack)
-	} else {
-		// Attack a card
-		if targetIndex < 0 || targetIndex >= len(defender.Field) {
-			return fmt.Errorf("invalid target index")
-		}
-
-		targetCard := defender.Field[targetIndex]
-
-		// Battle calculation
-		if attackCard.Attack > targetCard.Defense {
-			// Destroy target card
-			defender.Field = append(defender.Field[:targetIndex], defender.Field[targetIndex+1:]...)
-			defender.Graveyard = append(defender.Graveyard, targetCard)
-			game.LastAction = fmt.Sprintf("%s destroyed %s", attackCard.Name, targetCard.Name)
-		} else if attackCard.Attack < targetCard.Defense {
-			// Destroy attacker
-			attacker.Field = append(attacker.Field[:attackerIndex], attacker.Field[attackerIndex+1:]...)
-			attacker.Graveyard = append(attacker.Graveyard, attackCard)
-			game.LastAction = fmt.Sprintf("%s was destroyed by %s", attackCard.Name, targetCard.Name)
-		} else {
-			// Both destroyed
-			attacker.Field = append(attacker.Field[:attackerIndex], attacker.Field[attackerIndex+1:]...)
-			attacker.Graveyard = append(attacker.Graveyard, attackCard)
-			defender.Field = append(defender.Field[:targetIndex], defender.Field[targetIndex+1:]...)
-			defender.Graveyard = append(defender.Graveyard, targetCard)
-			game.LastAction = "Both cards destroyed"
-		}
+	if attackCard.HasAttacked {
+		return fmt.Errorf("card has already attacked this turn")
 	}
-
-	// Check win condition
-	if defender.HP <= 0 {
-		game.GameOver = true
-		game.Winner = playerID
+	if attackCard.HasModifier(ModifierStun) {
+		return fmt.Errorf("card is stunned")
 	}
-
-	be.notifyStateChange(game)
-	return nil
-}
-
-// EndTurn ends the current player's turn
-func (be *BattleEngine) EndTurn(gameID, playerID string) error {
-	be.mu.Lock()
-	defer be.mu.Unlock()
-
-	game, exists := be.games[gameID]
-	if !exists {
-		return fmt.Errorf("game not found")
+	if attackCard.SummoningSick(game.TurnCount, game.Rules) {
+		return fmt.Errorf("card cannot attack the turn it is played")
 	}
 
-	if game.CurrentTurn != playerID {
-		return fmt.Errorf("not your turn")
-	}
+	return checkAttackTarget(defender, targetID)
+}
 
-	// Switch turn
-	if game.CurrentTurn == game.Player1.ID {
-		game.CurrentTurn = game.Player2.ID
-	} else {
-		game.CurrentTurn = game.Player1.ID
+// attack declares an attack with a card and puts it on the stack
+func (be *BattleEngine) attack(game *GameState, attacker *Player, attackerID, targetID string) error {
+	if err := be.checkAttack(game, attacker, attackerID, targetID); err != nil {
+		return err
 	}
 
-	// Increment turn count
-	game.TurnCount++
-
-	// Reset phase
-	game.Phase = PhaseDrawn
-
-	// Increase mana for new turn player
-	currentPlayer := be.getPlayer(game, game.CurrentTurn)
-	if currentPlayer.MaxMana < 10 {
-		currentPlayer.MaxMana++
-	}
-	currentPlayer.Mana = currentPlayer.MaxMana
+	attackerIndex := findCard(attacker.Field, attackerID)
+	attacker.Field[attackerIndex].HasAttacked = true
+	attacker.Field[attackerIndex].removeKeyword(KeywordStealth)
+	attackCard := attacker.Field[attackerIndex]
 
-	game.LastAction = fmt.Sprintf("%s ended turn", playerID)
-	be.notifyStateChange(game)
+	event := Event{Type: EventAttackDeclared, PlayerID: attacker.ID, CardID: attackCard.ID, InstanceID: attackCard.InstanceID, CardName: attackCard.Name}
+	be.emit(game, event)
 
+	be.declare(game, StackItem{
+		PlayerID: attacker.ID,
+		Action:   Action{Type: ActionAttack, AttackerID: attackerID, TargetID: targetID},
+		Card:     attackCard,
+		Event:    event,
+	})
 	return nil
 }
 
-// ChangePhase changes the game phase
-func (be *BattleEngine) ChangePhase(gameID, playerID string, phase GamePhase) error {
-	be.mu.Lock()
-	defer be.mu.Unlock()
+// resolveAttack carries out an attack as it leaves the stack
+func (be *BattleEngine) resolveAttack(game *GameState, attacker *Player, item StackItem) {
+	defender := be.getOpponent(game, attacker.ID)
 
-	game, exists := be.games[gameID]
-	if !exists {
-		return fmt.Errorf("game not found")
+	// Abilities and responses may have removed either card since the declaration
+	attackerIndex := findCard(attacker.Field, item.Action.AttackerID)
+	if attackerIndex == -1 {
+		game.LastAction = fmt.Sprintf("%s's attack fizzled", item.Card.Name)
+		return
 	}
+	attackCard := attacker.Field[attackerIndex]
 
-	if game.CurrentTurn != playerID {
-		return fmt.Errorf("not your turn")
+	// Direct attack to player
+	if item.Action.TargetID == "" {
+		be.damagePlayer(game, defender, attackCard.Attack)
+		be.lifesteal(game, attacker, attackCard, attackCard.Attack)
+		game.LastAction = fmt.Sprintf("%s attacked directly for %d damage", attackCard.Name, attackCard.Attack)
+		return
 	}
 
-	game.Phase = phase
-	be.notifyStateChange(game)
+	targetIndex := findCard(defender.Field, item.Action.TargetID)
+	if targetIndex == -1 {
+		game.LastAction = fmt.Sprintf("%s's attack fizzled", attackCard.Name)
+		return
+	}
+	targetCard := defender.Field[targetIndex]
 
-	return nil
-}
+	// Battle calculation. A shield saves a card that would be destroyed.
+	if attackCard.Attack > targetCard.Defense {
+		if defender.Field[targetIndex].consumeShield() {
+			game.LastAction = fmt.Sprintf("%s's shield blocked %s", targetCard.Name, attackCard.Name)
+			return
+		}
 
-// GetGameState returns the current game state
-func (be *BattleEngine) GetGameState(gameID string) (*GameState, error) {
-	be.mu.RLock()
-	defer be.mu.RUnlock()
+		// Destroy target card
+		be.destroyCard(game, defender, targetIndex)
+		game.LastAction = fmt.Sprintf("%s destroyed %s", attackCard.Name, targetCard.Name)
+		dealt := targetCard.Defense
 
-	game, exists := be.games[gameID]
-	if !exists {
-		return nil, fmt.Errorf("game not found")
-	}
+		if attackCard.HasKeyword(KeywordPiercing) {
+			excess := attackCard.Attack - targetCard.Defense
+			be.damagePlayer(game, defender, excess)
+			game.LastAction = fmt.Sprintf("%s destroyed %s and pierced for %d damage", attackCard.Name, targetCard.Name, excess)
+			dealt += excess
+		}
+		be.lifesteal(game, attacker, attackCard, dealt)
+	} else if attackCard.Attack < targetCard.Defense {
+		if attacker.Field[attackerIndex].consumeShield() {
+			game.LastAction = fmt.Sprintf("%s's shield saved it from %s", attackCard.Name, targetCard.Name)
+			return
+		}
 
-	return game, nil
+		// Destroy attacker
+		be.destroyCard(game, attacker, attackerIndex)
+		game.LastAction = fmt.Sprintf("%s was destroyed by %s", attackCard.Name, targetCard.Name)
+	} else {
+		// Both destroyed, unless shielded
+		attackerShielded := attacker.Field[attackerIndex].consumeShield()
+		targetShielded := defender.Field[targetIndex].consumeShield()
+		if !attackerShielded {
+			removeFieldCard(attacker, attackerIndex)
+		}
+		if !targetShielded {
+			removeFieldCard(defender, targetIndex)
+			be.lifesteal(game, attacker, attackCard, targetCard.Defense)
+		}
+		be.recomputeStats(game)
+		if !attackerShielded {
+			be.emit(game, Event{Type: EventCardDestroyed, PlayerID: attacker.ID, CardID: attackCard.ID, InstanceID: attackCard.InstanceID, CardName: attackCard.Name})
+		}
+		if !targetShielded {
+			be.emit(game, Event{Type: EventCardDestroyed, PlayerID: defender.ID, CardID: targetCard.ID, InstanceID: targetCard.InstanceID, CardName: targetCard.Name})
+		}
+		game.LastAction = "Both cards destroyed"
+		if attackerShielded || targetShielded {
+			game.LastAction = fmt.Sprintf("%s and %s clashed", attackCard.Name, targetCard.Name)
+		}
+	}
 }
 
-// RegisterCallback registers a callback for state changes
-func (be *BattleEngine) RegisterCallback(gameID string, callback func(*GameState)) {
-	be.mu.Lock()
-	defer be.mu.Unlock()
-
-	be.callbacks[gameID] = callback
+// GetGameState returns a snapshot of the current game state
+func (be *BattleEngine) GetGameState(gameID string) (*GameState, error) {
+	var snapshot *GameState
+	err := be.run(gameID, func(m *match) error {
+		snapshot = m.game.Snapshot()
+		return nil
+	})
+	return snapshot, err
 }
 
 // Helper functions
@@ -389,69 +486,75 @@ func (be *BattleEngine) getOpponent(game *GameState, playerID string) *Player {
 	return nil
 }
 
-func (be *BattleEngine) notifyStateChange(game *GameState) {
-	if callback, exists := be.callbacks[game.ID]; exists {
-		go callback(game)
+// instantiateDeck copies a deck list, giving every card an instance ID that
+// is unique within the match. Copies of the same definition share Card.ID
+// but never InstanceID.
+func instantiateDeck(prefix string, deck []Card) []Card {
+	cards := make([]Card, len(deck))
+	for i, card := range deck {
+		card.InstanceID = fmt.Sprintf("%s-%d", prefix, i+1)
+		cards[i] = card
 	}
+	return cards
 }
 
-func (be *BattleEngine) applyArchetypeBonus(player *Player, card *Card) {
-	// Count archetype cards on field
-	archetypeCount := make(map[Archetype]int)
-	for _, fieldCard := range player.Field {
-		archetypeCount[fieldCard.Archetype]++
-	}
-
-	// Egyptian bonus: +10% attack for each Egyptian card
-	if card.Archetype == ArchetypeEgyptian {
-		bonus := float32(archetypeCount[ArchetypeEgyptian]) * 0.1
-		card.Attack = int(float32(card.Attack) * (1 + bonus))
-	}
-
-	// Greek bonus: +10% defense for each Greek card
-	if card.Archetype == ArchetypeGreek {
-		bonus := float32(archetypeCount[ArchetypeGreek]) * 0.1
-		card.Defense = int(float32(card.Defense) * (1 + bonus))
+// findCard returns the index of the card with the given instance ID, or -1
+func findCard(cards []Card, instanceID string) int {
+	if instanceID == "" {
+		return -1
 	}
-}
-
-func (be *BattleEngine) applyCardEffect(game *GameState, player *Player, card Card) {
-	switch card.EffectType {
-	case "draw":
-		drawCards(player, 1)
-	case "damage":
-		opponent := be.getOpponent(game, player.ID)
-		opponent.HP -= 500
-	case "heal":
-		player.HP += 500
-		if player.HP > 8000 {
-			player.HP = 8000
+	for i, card := range cards {
+		if card.InstanceID == instanceID {
+			return i
 		}
-	case "mana":
-		player.Mana += 1
 	}
+	return -1
 }
 
-func shuffleDeck(deck []Card) []Card {
+func shuffleDeck(rng *rand.Rand, deck []Card) []Card {
 	shuffled := make([]Card, len(deck))
 	copy(shuffled, deck)
-	rand.Shuffle(len(shuffled), func(i, j int) {
+	rng.Shuffle(len(shuffled), func(i, j int) {
 		shuffled[i], shuffled[j] = shuffled[j], shuffled[i]
 	})
 	return shuffled
 }
 
-func drawCards(player *Player, count int) bool {
+func drawCards(rules RuleSet, player *Player, count int) bool {
 	for i := 0; i < count; i++ {
 		if len(player.Deck) == 0 {
 			return false // Deck out condition
 		}
-		player.Hand = append(player.Hand, player.Deck[0])
+		card := player.Deck[0]
 		player.Deck = player.Deck[1:]
+
+		// Cards drawn into a full hand are burned
+		if rules.MaxHandSize > 0 && len(player.Hand) >= rules.MaxHandSize {
+			player.Graveyard = append(player.Graveyard, card)
+			continue
+		}
+		player.Hand = append(player.Hand, card)
 	}
 	return true
 }
 
+// newPlayer creates a player at the starting values of the rules
+func newPlayer(id string, deck []Card, rules RuleSet) *Player {
+	return &Player{
+		ID:             id,
+		HP:             rules.StartingHP,
+		Mana:           rules.StartingMana,
+		MaxMana:        rules.StartingMaxMana,
+		Deck:           deck,
+		Hand:           []Card{},
+		Field:          []Card{},
+		Graveyard:      []Card{},
+		Traps:          []Card{},
+		ArchetypeBonus: make(map[Archetype]float32),
+		TimeBank:       rules.MatchTime,
+	}
+}
+
 // API Response type
 type APIResponse struct {
 	Success bool        `json:"success"`
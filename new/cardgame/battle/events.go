@@ -0,0 +1,140 @@
+package battle
+
+// EventType identifies something that happened during a match
+type EventType string
+
+const (
+	EventCardPlayed     EventType = "card_played"
+	EventCardDestroyed  EventType = "card_destroyed"
+	EventAttackDeclared EventType = "attack_declared"
+	EventTurnStarted    EventType = "turn_started"
+	EventTurnEnded      EventType = "turn_ended"
+	EventPlayerDamaged  EventType = "player_damaged"
+	EventTrapTriggered  EventType = "trap_triggered"
+)
+
+// maxEventsPerAction bounds how many events a single action may cause,
+// so triggered abilities that keep re-triggering each other terminate.
+// Events past the limit are dropped and the action's result says so.
+const maxEventsPerAction = 64
+
+// Event is emitted by the engine whenever something happens that cards can react to
+type Event struct {
+	Type       EventType `json:"type"`
+	PlayerID   string    `json:"player_id"`
+	CardID     string    `json:"card_id,omitempty"`
+	InstanceID string    `json:"instance_id,omitempty"`
+	CardName   string    `json:"card_name,omitempty"`
+	Amount     int       `json:"amount,omitempty"`
+}
+
+// TriggerScope restricts whose events a triggered ability reacts to
+type TriggerScope string
+
+const (
+	ScopeAny      TriggerScope = "any"
+	ScopeOwn      TriggerScope = "own"
+	ScopeOpponent TriggerScope = "opponent"
+)
+
+// TriggeredAbility is an effect a field card resolves when a matching event occurs
+type TriggeredAbility struct {
+	On     EventType    `json:"on"`
+	Scope  TriggerScope `json:"scope,omitempty"`
+	Effect CardEffect   `json:"effect"`
+}
+
+// matches reports whether the ability fires for event on a card controlled by ownerID
+func (t TriggeredAbility) matches(event Event, ownerID string) bool {
+	if t.On != event.Type {
+		return false
+	}
+
+	switch t.Scope {
+	case ScopeOwn:
+		return event.PlayerID == ownerID
+	case ScopeOpponent:
+		return event.PlayerID != ownerID
+	}
+	return true
+}
+
+// eventBus queues the events of a single match and dispatches them one at a time
+type eventBus struct {
+	queue       []Event
+	dispatching bool
+	processed   int
+	dropped     int
+	history     []Event
+}
+
+func newEventBus() *eventBus {
+	return &eventBus{}
+}
+
+// startAction resets the event limit for the next action
+func (bus *eventBus) startAction() {
+	bus.processed = 0
+	bus.dropped = 0
+}
+
+// drainHistory returns the events dispatched since the last call and forgets them
+func (bus *eventBus) drainHistory() []Event {
+	history := bus.history
+	bus.history = nil
+	return history
+}
+
+// emit queues an event and, unless a dispatch is already running, resolves
+// it together with every event it causes in first-in-first-out order
+func (be *BattleEngine) emit(game *GameState, event Event) {
+	bus := game.events
+	bus.queue = append(bus.queue, event)
+	if bus.dispatching {
+		return
+	}
+
+	bus.dispatching = true
+	for len(bus.queue) > 0 {
+		if bus.processed >= maxEventsPerAction {
+			// Drop whatever is left instead of looping forever
+			bus.dropped += len(bus.queue)
+			bus.queue = nil
+			break
+		}
+
+		next := bus.queue[0]
+		bus.queue = bus.queue[1:]
+		bus.processed++
+		bus.history = append(bus.history, next)
+
+		be.runTriggers(game, next)
+	}
+	bus.dispatching = false
+}
+
+// runTriggers resolves every triggered ability that reacts to event. The current
+// player's field resolves before the opponent's, each from left to right.
+// Triggered archetype synergies resolve after each player's cards. Face-down
+// traps do not fire here; they are flipped as responses on the stack.
+func (be *BattleEngine) runTriggers(game *GameState, event Event) {
+	active := be.getPlayer(game, game.CurrentTurn)
+	owners := []*Player{active, be.getOpponent(game, active.ID)}
+
+	for _, owner := range owners {
+		// Copy the field so abilities that destroy cards do not disturb iteration
+		field := append([]Card(nil), owner.Field...)
+		for _, card := range field {
+			for _, ability := range card.Triggers {
+				// An earlier ability may have destroyed the card
+				if findCard(owner.Field, card.InstanceID) < 0 {
+					break
+				}
+				if ability.matches(event, owner.ID) {
+					be.resolveEffect(game, owner, ability.Effect, &event)
+				}
+			}
+		}
+		be.runSynergyTriggers(game, owner, event)
+	}
+}
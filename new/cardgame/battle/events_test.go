@@ -0,0 +1,39 @@
+package battle_test
+
+import (
+	"strings"
+	"testing"
+
+	"cardgame/battle"
+)
+
+func TestEventLimitAppliesPerAction(t *testing.T) {
+	// Thorns answers every point of damage with another, forever
+	thorns := creature("Thorns", 100, 100)
+	thorns.Triggers = []battle.TriggeredAbility{{
+		On:     battle.EventPlayerDamaged,
+		Effect: battle.CardEffect{Kind: battle.EffectDamage, Amount: 1, Target: battle.TargetOpponent},
+	}}
+	// Jolt starts two chains of triggers in one action
+	jolt := battle.Card{ID: "jolt", Type: battle.CardSpell, Name: "Jolt", Cost: 1, Effects: append(spark.Effects, spark.Effects...)}
+	be := battle.NewBattleEngine()
+	g := newScriptedMatch(t, be, []battle.Card{thorns, jolt, jolt}, nil)
+	s := play(t, be, g.ID, "A", "Thorns")
+
+	for _, card := range s.Player1.Hand {
+		if card.Name != "Jolt" {
+			continue
+		}
+		mustApply(t, be, g.ID, "A", battle.Action{Type: battle.ActionPlayCard, CardID: card.InstanceID})
+		s = mustApply(t, be, g.ID, "B", battle.Action{Type: battle.ActionPass})
+		if !strings.Contains(s.LastAction, "event limit reached") {
+			t.Fatalf("got %q, want the dropped events reported", s.LastAction)
+		}
+
+		// Every action gets the full limit, however many came before it
+		entries, _ := be.GetActionLog(g.ID)
+		if events := entries[len(entries)-1].Events; len(events) != 64 {
+			t.Fatalf("Jolt caused %d events, want the limit of 64", len(events))
+		}
+	}
+}
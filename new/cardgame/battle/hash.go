@@ -0,0 +1,298 @@
+package battle
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"hash"
+	"math"
+	"sort"
+	"time"
+)
+
+// Hash returns a canonical SHA-256 digest of the game, hex encoded. Every
+// exported field goes in, in a fixed order, so two states hash alike
+// exactly when they are equal. The engine's internals are left out, so a
+// snapshot hashes like the game it was taken from.
+func (g *GameState) Hash() string {
+	c := newCanonical("cardgame/state/v1")
+	c.str(g.ID)
+	c.player(g.Player1)
+	c.player(g.Player2)
+	c.str(g.CurrentTurn)
+	c.int(int64(g.TurnCount))
+	c.str(string(g.Phase))
+	c.str(g.Winner)
+	c.bool(g.GameOver)
+	c.str(g.LastAction)
+	c.int(int64(g.Version))
+	c.int(g.Seed)
+	c.rules(g.Rules)
+	c.result(g.Result)
+	c.stack(g.Stack)
+	c.str(g.Priority)
+	c.duration(g.TurnTimeLeft)
+	c.str(g.ClockHolder)
+	return c.sum()
+}
+
+// Hash returns a canonical SHA-256 digest of the view, hex encoded. A
+// client can hash the view it decoded and compare it with the hash the
+// server sent to make sure both see the same game.
+func (v *GameView) Hash() string {
+	c := newCanonical("cardgame/view/v1")
+	c.str(v.ID)
+	c.str(v.ViewerID)
+	c.playerView(v.Player1)
+	c.playerView(v.Player2)
+	c.str(v.CurrentTurn)
+	c.int(int64(v.TurnCount))
+	c.str(string(v.Phase))
+	c.str(v.Winner)
+	c.bool(v.GameOver)
+	c.str(v.LastAction)
+	c.int(int64(v.Version))
+	c.rules(v.Rules)
+	c.result(v.Result)
+	c.stack(v.Stack)
+	c.str(v.Priority)
+	c.duration(v.TurnTimeLeft)
+	c.str(v.ClockHolder)
+	return c.sum()
+}
+
+// canonical writes values into a hash in an unambiguous form: numbers as
+// varints, strings and lists prefixed with their length, and maps in key
+// order. A nil list and an empty one write the same.
+type canonical struct {
+	h   hash.Hash
+	buf [binary.MaxVarintLen64]byte
+}
+
+func newCanonical(domain string) *canonical {
+	c := &canonical{h: sha256.New()}
+	c.str(domain)
+	return c
+}
+
+func (c *canonical) sum() string {
+	return hex.EncodeToString(c.h.Sum(nil))
+}
+
+func (c *canonical) int(n int64) {
+	c.h.Write(c.buf[:binary.PutVarint(c.buf[:], n)])
+}
+
+func (c *canonical) bool(b bool) {
+	if b {
+		c.int(1)
+	} else {
+		c.int(0)
+	}
+}
+
+func (c *canonical) str(s string) {
+	c.int(int64(len(s)))
+	c.h.Write([]byte(s))
+}
+
+func (c *canonical) duration(d time.Duration) {
+	c.int(int64(d))
+}
+
+func (c *canonical) player(p *Player) {
+	c.bool(p != nil)
+	if p == nil {
+		return
+	}
+	c.str(p.ID)
+	c.str(p.Name)
+	c.int(int64(p.HP))
+	c.int(int64(p.Mana))
+	c.int(int64(p.MaxMana))
+	c.cards(p.Deck)
+	c.cards(p.Hand)
+	c.cards(p.Field)
+	c.cards(p.Graveyard)
+	c.cards(p.Traps)
+	c.bonus(p.ArchetypeBonus)
+	c.int(int64(p.Fatigue))
+	c.bool(p.MulliganDone)
+	c.duration(p.TimeBank)
+	c.int(int64(p.Timeouts))
+}
+
+func (c *canonical) playerView(p *PlayerView) {
+	c.bool(p != nil)
+	if p == nil {
+		return
+	}
+	c.str(p.ID)
+	c.str(p.Name)
+	c.int(int64(p.HP))
+	c.int(int64(p.Mana))
+	c.int(int64(p.MaxMana))
+	c.cards(p.Hand)
+	c.int(int64(p.HandCount))
+	c.int(int64(p.DeckCount))
+	c.cards(p.Field)
+	c.cards(p.Graveyard)
+	c.cards(p.Traps)
+	c.int(int64(p.TrapCount))
+	c.bonus(p.ArchetypeBonus)
+	c.int(int64(p.Fatigue))
+	c.bool(p.MulliganDone)
+	c.duration(p.TimeBank)
+	c.int(int64(p.Timeouts))
+}
+
+// bonus writes the archetype bonuses sorted by archetype
+func (c *canonical) bonus(bonus map[Archetype]float32) {
+	archetypes := make([]string, 0, len(bonus))
+	for archetype := range bonus {
+		archetypes = append(archetypes, string(archetype))
+	}
+	sort.Strings(archetypes)
+
+	c.int(int64(len(archetypes)))
+	for _, archetype := range archetypes {
+		c.str(archetype)
+		c.int(int64(math.Float32bits(bonus[Archetype(archetype)])))
+	}
+}
+
+func (c *canonical) cards(cards []Card) {
+	c.int(int64(len(cards)))
+	for _, card := range cards {
+		c.card(card)
+	}
+}
+
+func (c *canonical) card(card Card) {
+	c.str(card.ID)
+	c.str(card.InstanceID)
+	c.str(string(card.Type))
+	c.str(card.Name)
+	c.str(string(card.Archetype))
+	c.int(int64(card.Attack))
+	c.int(int64(card.Defense))
+	c.int(int64(card.Cost))
+	c.str(card.Effect)
+
+	c.int(int64(len(card.Effects)))
+	for _, effect := range card.Effects {
+		c.effect(effect)
+	}
+	c.int(int64(len(card.Triggers)))
+	for _, trigger := range card.Triggers {
+		c.str(string(trigger.On))
+		c.str(string(trigger.Scope))
+		c.effect(trigger.Effect)
+	}
+	c.int(int64(len(card.Keywords)))
+	for _, keyword := range card.Keywords {
+		c.str(string(keyword))
+	}
+
+	c.bool(card.HasAttacked)
+	c.int(int64(card.EnteredTurn))
+	c.cards(card.Attached)
+	c.int(int64(card.BaseAttack))
+	c.int(int64(card.BaseDefense))
+	c.int(int64(len(card.BaseKeywords)))
+	for _, keyword := range card.BaseKeywords {
+		c.str(string(keyword))
+	}
+	c.int(int64(card.Damage))
+	c.int(int64(len(card.Modifiers)))
+	for _, modifier := range card.Modifiers {
+		c.modifier(modifier)
+	}
+}
+
+func (c *canonical) effect(effect CardEffect) {
+	c.str(string(effect.Kind))
+	c.int(int64(effect.Amount))
+	c.str(string(effect.Target))
+
+	c.bool(effect.Condition != nil)
+	if effect.Condition != nil {
+		c.str(string(effect.Condition.Kind))
+		c.int(int64(effect.Condition.Value))
+		c.str(string(effect.Condition.Archetype))
+	}
+	c.bool(effect.Modifier != nil)
+	if effect.Modifier != nil {
+		c.modifier(*effect.Modifier)
+	}
+}
+
+func (c *canonical) modifier(modifier Modifier) {
+	c.str(string(modifier.Kind))
+	c.int(int64(modifier.Attack))
+	c.int(int64(modifier.Defense))
+	c.int(int64(modifier.Amount))
+	c.int(int64(modifier.Turns))
+}
+
+func (c *canonical) rules(rules RuleSet) {
+	c.str(rules.Name)
+	c.int(int64(rules.StartingHP))
+	c.int(int64(rules.StartingMana))
+	c.int(int64(rules.StartingMaxMana))
+	c.int(int64(rules.ManaPerTurn))
+	c.int(int64(rules.MaxMana))
+	c.int(int64(rules.OpeningHand))
+	c.int(int64(rules.MaxHandSize))
+	c.int(int64(rules.MaxFieldSize))
+	c.int(int64(rules.DeckSize))
+	c.bool(rules.Mulligan)
+	c.bool(rules.SummoningSickness)
+	c.str(string(rules.DeckOut))
+	c.int(int64(rules.FatigueDamage))
+	c.int(int64(rules.ArchetypeBonusPercent))
+	c.duration(rules.TurnTime)
+	c.duration(rules.MatchTime)
+	c.int(int64(rules.MaxTimeouts))
+	c.duration(rules.ResponseTime)
+}
+
+func (c *canonical) result(result *MatchResult) {
+	c.bool(result != nil)
+	if result == nil {
+		return
+	}
+	c.str(result.Winner)
+	c.str(result.Loser)
+	c.bool(result.Draw)
+	c.str(string(result.Reason))
+	c.int(int64(result.Turn))
+}
+
+func (c *canonical) stack(stack []StackItem) {
+	c.int(int64(len(stack)))
+	for _, item := range stack {
+		c.str(item.PlayerID)
+		c.action(item.Action)
+		c.card(item.Card)
+		c.str(string(item.Event.Type))
+		c.str(item.Event.PlayerID)
+		c.str(item.Event.CardID)
+		c.str(item.Event.InstanceID)
+		c.str(item.Event.CardName)
+		c.int(int64(item.Event.Amount))
+	}
+}
+
+func (c *canonical) action(action Action) {
+	c.str(string(action.Type))
+	c.str(action.CardID)
+	c.str(action.AttackerID)
+	c.str(action.TargetID)
+	c.str(string(action.Phase))
+	c.int(int64(len(action.CardIDs)))
+	for _, cardID := range action.CardIDs {
+		c.str(cardID)
+	}
+	c.str(string(action.Reason))
+}
@@ -0,0 +1,131 @@
+package battle_test
+
+import (
+	"encoding/json"
+	"math/rand"
+	"testing"
+
+	"cardgame/battle"
+	"cardgame/shared"
+)
+
+func TestHashMatchesEngineAndSnapshot(t *testing.T) {
+	be := battle.NewBattleEngine()
+	g := newMatch(t, be, 5)
+	rng := rand.New(rand.NewSource(5))
+
+	for i := 0; i < 100; i++ {
+		s, _ := be.GetGameState(g.ID)
+		if s.GameOver {
+			break
+		}
+		if s.Snapshot().Hash() != s.Hash() {
+			t.Fatalf("version %d: snapshot hashes differently", s.Version)
+		}
+
+		player := actor(s)
+		legal, _ := be.LegalActions(g.ID, player)
+		result, err := be.Apply(g.ID, player, legal[rng.Intn(len(legal))])
+		if err != nil {
+			t.Fatalf("applying legal action: %v", err)
+		}
+		after, _ := be.GetGameState(g.ID)
+		if result.StateHash != after.Hash() {
+			t.Fatalf("version %d: reported hash differs from the state's", after.Version)
+		}
+	}
+}
+
+func TestViewHashSurvivesJSON(t *testing.T) {
+	be := battle.NewBattleEngine()
+	g := newMatch(t, be, 9)
+	playRandom(t, be, g.ID, rand.New(rand.NewSource(9)), 300, func(s *battle.GameState) {
+		for _, viewer := range []string{"A", "B", ""} {
+			view := s.ViewFor(viewer)
+
+			// Decode the way clients do: into generic data, then into a view
+			raw, err := json.Marshal(shared.Message{Type: shared.MsgGameUpdate, Data: map[string]interface{}{"gameState": view}})
+			if err != nil {
+				t.Fatalf("encoding view: %v", err)
+			}
+			var msg shared.Message
+			if err := json.Unmarshal(raw, &msg); err != nil {
+				t.Fatalf("decoding message: %v", err)
+			}
+			decoded, err := shared.ConvertToGameView(msg.Data.(map[string]interface{})["gameState"])
+			if err != nil {
+				t.Fatalf("converting view: %v", err)
+			}
+			if decoded.Hash() != view.Hash() {
+				t.Fatalf("version %d viewer %q: view hashes differently after JSON", s.Version, viewer)
+			}
+		}
+	})
+}
+
+func TestHashIgnoresMapOrder(t *testing.T) {
+	be := battle.NewBattleEngine()
+	g := newMatch(t, be, 1)
+	archetypes := []battle.Archetype{"egyptian", "greek", "norse", "celtic", "aztec"}
+
+	forward, backward := g.Snapshot(), g.Snapshot()
+	forward.Player1.ArchetypeBonus = map[battle.Archetype]float32{}
+	backward.Player1.ArchetypeBonus = map[battle.Archetype]float32{}
+	for i, archetype := range archetypes {
+		forward.Player1.ArchetypeBonus[archetype] = float32(i) / 10
+		last := archetypes[len(archetypes)-1-i]
+		backward.Player1.ArchetypeBonus[last] = float32(len(archetypes)-1-i) / 10
+	}
+
+	want := forward.Hash()
+	for i := 0; i < 50; i++ {
+		if forward.Hash() != want || backward.Hash() != want {
+			t.Fatalf("hash depends on map order")
+		}
+	}
+}
+
+func TestHashChangesWithAnyField(t *testing.T) {
+	be := battle.NewBattleEngine()
+	g := newMatch(t, be, 2)
+	base := playRandom(t, be, g.ID, rand.New(rand.NewSource(2)), 40, nil)
+
+	changes := map[string]func(s *battle.GameState){
+		"hp":         func(s *battle.GameState) { s.Player2.HP-- },
+		"mana":       func(s *battle.GameState) { s.Player1.Mana++ },
+		"hand card":  func(s *battle.GameState) { s.Player1.Hand[0].Attack++ },
+		"deck order": func(s *battle.GameState) { s.Player1.Deck[0], s.Player1.Deck[1] = s.Player1.Deck[1], s.Player1.Deck[0] },
+		"keyword": func(s *battle.GameState) {
+			s.Player1.Hand[0].Keywords = append(s.Player1.Hand[0].Keywords, battle.KeywordRush)
+		},
+		"bonus":          func(s *battle.GameState) { s.Player1.ArchetypeBonus["greek"] += 0.5 },
+		"phase":          func(s *battle.GameState) { s.Phase = battle.PhaseEnd },
+		"turn":           func(s *battle.GameState) { s.TurnCount++ },
+		"version":        func(s *battle.GameState) { s.Version++ },
+		"priority":       func(s *battle.GameState) { s.Priority = "B" },
+		"rules":          func(s *battle.GameState) { s.Rules.ResponseTime++ },
+		"result":         func(s *battle.GameState) { s.Result = &battle.MatchResult{Winner: "A", Loser: "B"} },
+		"stack":          func(s *battle.GameState) { s.Stack = append(s.Stack, battle.StackItem{PlayerID: "A"}) },
+		"time bank":      func(s *battle.GameState) { s.Player2.TimeBank++ },
+		"mulligan done":  func(s *battle.GameState) { s.Player2.MulliganDone = !s.Player2.MulliganDone },
+		"graveyard":      func(s *battle.GameState) { s.Player1.Graveyard = append(s.Player1.Graveyard, battle.Card{}) },
+		"player swapped": func(s *battle.GameState) { s.Player1, s.Player2 = s.Player2, s.Player1 },
+	}
+	for name, change := range changes {
+		t.Run(name, func(t *testing.T) {
+			changed := base.Snapshot()
+			change(changed)
+			if changed.Hash() == base.Hash() {
+				t.Fatalf("hash did not change")
+			}
+		})
+	}
+
+	// A view is hashed on what the viewer sees
+	view := base.ViewFor("A")
+	want := view.Hash()
+	view.Player2.HandCount++
+	if view.Hash() == want {
+		t.Fatalf("view hash did not change with the opponent's hand count")
+	}
+}
@@ -0,0 +1,170 @@
+package battle_test
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+	"time"
+
+	"cardgame/battle"
+	"cardgame/game"
+)
+
+// newMatch starts a seeded standard match between A and B
+func newMatch(t *testing.T, be *battle.BattleEngine, seed int64) *battle.GameState {
+	t.Helper()
+	return newMatchWithRules(t, be, battle.StandardRules(), seed)
+}
+
+// timedRules are the standard rules with the time controls of online play
+func timedRules() battle.RuleSet {
+	rules := battle.StandardRules()
+	rules.TurnTime = 90 * time.Second
+	rules.MaxTimeouts = 3
+	rules.ResponseTime = 15 * time.Second
+	return rules
+}
+
+// newMatchWithRules starts a seeded match between A and B under rules
+func newMatchWithRules(t *testing.T, be *battle.BattleEngine, rules battle.RuleSet, seed int64) *battle.GameState {
+	t.Helper()
+	decks := game.NewDeckBuilder()
+	g, err := be.CreateSeededMatch("A", "B", decks.CreateEgyptianDeck(), decks.CreateGreekDeck(), rules, seed)
+	if err != nil {
+		t.Fatalf("creating match: %v", err)
+	}
+	return g
+}
+
+// actor returns the player who is to move next
+func actor(s *battle.GameState) string {
+	switch {
+	case s.Phase == battle.PhaseMulligan && !s.Player1.MulliganDone:
+		return s.Player1.ID
+	case s.Phase == battle.PhaseMulligan:
+		return s.Player2.ID
+	case s.Priority != "":
+		return s.Priority
+	}
+	return s.CurrentTurn
+}
+
+// playRandom plays up to steps random legal moves, calling visit with
+// every state along the way, the first and last included
+func playRandom(t *testing.T, be *battle.BattleEngine, gameID string, rng *rand.Rand, steps int, visit func(*battle.GameState)) *battle.GameState {
+	t.Helper()
+	for i := 0; ; i++ {
+		s, err := be.GetGameState(gameID)
+		if err != nil {
+			t.Fatalf("reading state: %v", err)
+		}
+		if visit != nil {
+			visit(s)
+		}
+		if s.GameOver || i == steps {
+			return s
+		}
+
+		player := actor(s)
+		legal, err := be.LegalActions(gameID, player)
+		if err != nil || len(legal) == 0 {
+			t.Fatalf("no legal action for %s: %v", player, err)
+		}
+		if _, err := be.Apply(gameID, player, legal[rng.Intn(len(legal))]); err != nil {
+			t.Fatalf("applying legal action: %v", err)
+		}
+	}
+}
+
+// newScriptedMatch starts a match between A and B in which each player
+// holds exactly the given cards, so a test can play them by name. Hands are
+// padded to the same size with cards too dear to play; both decks start
+// empty and drawing deals fatigue. configure may adjust the rules further.
+func newScriptedMatch(t *testing.T, be *battle.BattleEngine, hand1, hand2 []battle.Card, configure ...func(*battle.RuleSet)) *battle.GameState {
+	t.Helper()
+	size := max(len(hand1), len(hand2), 1)
+	pad := func(hand []battle.Card) []battle.Card {
+		deck := append([]battle.Card(nil), hand...)
+		for len(deck) < size {
+			deck = append(deck, battle.Card{ID: "filler", Name: "Filler", Attack: 1, Defense: 1, Cost: 99})
+		}
+		return deck
+	}
+
+	rules := battle.ClassicRules()
+	rules.OpeningHand = size
+	rules.DeckOut = battle.DeckOutFatigue
+	rules.FatigueDamage = 100
+	for _, change := range configure {
+		change(&rules)
+	}
+	g, err := be.CreateSeededMatch("A", "B", pad(hand1), pad(hand2), rules, 1)
+	if err != nil {
+		t.Fatalf("creating match: %v", err)
+	}
+	return g
+}
+
+// creature returns a creature card costing 1
+func creature(name string, attack, defense int, keywords ...battle.Keyword) battle.Card {
+	return battle.Card{ID: strings.ToLower(name), Type: battle.CardCreature, Name: name, Attack: attack, Defense: defense, Cost: 1, Keywords: keywords}
+}
+
+// instant returns an instant costing 1 with the given effects
+func instant(name string, effects ...battle.CardEffect) battle.Card {
+	return battle.Card{ID: strings.ToLower(name), Type: battle.CardInstant, Name: name, Cost: 1, Effects: effects}
+}
+
+// instanceID returns the instance ID of the first card called name
+func instanceID(t *testing.T, cards []battle.Card, name string) string {
+	t.Helper()
+	for _, card := range cards {
+		if card.Name == name {
+			return card.InstanceID
+		}
+	}
+	t.Fatalf("no card called %s", name)
+	return ""
+}
+
+// state reads the current state of a game
+func state(t *testing.T, be *battle.BattleEngine, gameID string) *battle.GameState {
+	t.Helper()
+	s, err := be.GetGameState(gameID)
+	if err != nil {
+		t.Fatalf("reading state: %v", err)
+	}
+	return s
+}
+
+// mustApply applies an action that the test expects to be accepted
+func mustApply(t *testing.T, be *battle.BattleEngine, gameID, playerID string, action battle.Action) *battle.GameState {
+	t.Helper()
+	if _, err := be.Apply(gameID, playerID, action); err != nil {
+		t.Fatalf("%s %v: %v", playerID, action, err)
+	}
+	return state(t, be, gameID)
+}
+
+// play plays the named card from the player's hand and lets the opponent
+// pass on it, so it resolves
+func play(t *testing.T, be *battle.BattleEngine, gameID, playerID, name string) *battle.GameState {
+	t.Helper()
+	s := state(t, be, gameID)
+	hand := s.Player1.Hand
+	if playerID == s.Player2.ID {
+		hand = s.Player2.Hand
+	}
+	s = mustApply(t, be, gameID, playerID, battle.Action{Type: battle.ActionPlayCard, CardID: instanceID(t, hand, name)})
+	for s.Priority != "" {
+		s = mustApply(t, be, gameID, s.Priority, battle.Action{Type: battle.ActionPass})
+	}
+	return s
+}
+
+// passTurn ends the current turn and has the next player draw
+func passTurn(t *testing.T, be *battle.BattleEngine, gameID string) *battle.GameState {
+	t.Helper()
+	s := mustApply(t, be, gameID, state(t, be, gameID).CurrentTurn, battle.Action{Type: battle.ActionEndTurn})
+	return mustApply(t, be, gameID, s.CurrentTurn, battle.Action{Type: battle.ActionDraw})
+}
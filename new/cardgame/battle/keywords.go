@@ -0,0 +1,121 @@
+package battle
+
+import "fmt"
+
+// Keyword is a static ability printed on a card
+type Keyword string
+
+const (
+	// KeywordRush lets a card attack on the turn it enters the field
+	KeywordRush Keyword = "rush"
+	// KeywordGuard must be attacked before any other card or the player
+	KeywordGuard Keyword = "guard"
+	// KeywordPiercing deals the attack in excess of a destroyed card's
+	// defense to the defending player
+	KeywordPiercing Keyword = "piercing"
+	// KeywordLifesteal heals the controller by the damage each of the
+	// card's attacks deals to the defending card or player
+	KeywordLifesteal Keyword = "lifesteal"
+	// KeywordStealth cannot be attacked until the card attacks for the first time
+	KeywordStealth Keyword = "stealth"
+)
+
+// AllKeywords lists every keyword in the order they are explained to players
+var AllKeywords = []Keyword{KeywordRush, KeywordGuard, KeywordPiercing, KeywordLifesteal, KeywordStealth}
+
+// Description returns the reminder text shown for the keyword
+func (k Keyword) Description() string {
+	switch k {
+	case KeywordRush:
+		return "can attack the turn it is played"
+	case KeywordGuard:
+		return "must be attacked first"
+	case KeywordPiercing:
+		return "excess attack damages the opponent"
+	case KeywordLifesteal:
+		return "heals you for the damage its attacks deal"
+	case KeywordStealth:
+		return "cannot be attacked until it attacks"
+	}
+	return string(k)
+}
+
+// HasKeyword reports whether the card has the given keyword
+func (c Card) HasKeyword(keyword Keyword) bool {
+	for _, k := range c.Keywords {
+		if k == keyword {
+			return true
+		}
+	}
+	return false
+}
+
+// SummoningSick reports whether a field card is still unable to attack
+// because it entered the field this turn
+func (c Card) SummoningSick(turn int, rules RuleSet) bool {
+	return rules.SummoningSickness && c.EnteredTurn == turn && !c.HasKeyword(KeywordRush)
+}
+
+// CanAttack reports whether a field card is ready to attack this turn
+func (c Card) CanAttack(turn int, rules RuleSet) bool {
+	return !c.HasAttacked && !c.SummoningSick(turn, rules) && !c.HasModifier(ModifierStun)
+}
+
+// lifesteal heals the controller of a card with Lifesteal by the damage
+// one of its attacks dealt
+func (be *BattleEngine) lifesteal(game *GameState, player *Player, card Card, dealt int) {
+	if card.HasKeyword(KeywordLifesteal) && dealt > 0 {
+		be.healPlayer(game, player, dealt)
+	}
+}
+
+// removeKeyword strips a keyword from the card, such as Stealth once it attacks
+func (c *Card) removeKeyword(keyword Keyword) {
+	var kept []Keyword
+	for _, k := range c.Keywords {
+		if k != keyword {
+			kept = append(kept, k)
+		}
+	}
+	c.Keywords = kept
+}
+
+// Targetable reports whether the card can be chosen as the target of an attack
+func (c Card) Targetable() bool {
+	return !c.HasKeyword(KeywordStealth)
+}
+
+// checkAttackTarget reports why targetID on the defender's field cannot be
+// attacked, if it cannot. An empty targetID is a direct attack, which is only
+// allowed while the defender has no card that can be attacked. While the
+// defender has a targetable card with Guard, only such cards can be attacked.
+func checkAttackTarget(defender *Player, targetID string) error {
+	targetable, guarded := false, false
+	for _, card := range defender.Field {
+		if card.Targetable() {
+			targetable = true
+			guarded = guarded || card.HasKeyword(KeywordGuard)
+		}
+	}
+
+	if targetID == "" {
+		if targetable {
+			return fmt.Errorf("cannot attack directly when opponent has cards")
+		}
+		return nil
+	}
+
+	index := findCard(defender.Field, targetID)
+	if index == -1 {
+		return fmt.Errorf("target not on field")
+	}
+
+	target := defender.Field[index]
+	if !target.Targetable() {
+		return fmt.Errorf("cannot attack a card with stealth")
+	}
+	if guarded && !target.HasKeyword(KeywordGuard) {
+		return fmt.Errorf("must attack a card with guard first")
+	}
+	return nil
+}
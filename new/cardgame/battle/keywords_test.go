@@ -0,0 +1,120 @@
+package battle_test
+
+import (
+	"strings"
+	"testing"
+
+	"cardgame/battle"
+)
+
+// attackTargets lists whom the attacker may attack, "" standing for the player
+func attackTargets(t *testing.T, be *battle.BattleEngine, gameID, playerID, attackerID string) []string {
+	t.Helper()
+	legal, err := be.LegalActions(gameID, playerID)
+	if err != nil {
+		t.Fatalf("listing legal actions: %v", err)
+	}
+	var targets []string
+	for _, action := range legal {
+		if action.Type == battle.ActionAttack && action.AttackerID == attackerID {
+			targets = append(targets, action.TargetID)
+		}
+	}
+	return targets
+}
+
+func TestGuardMustBeAttackedFirst(t *testing.T) {
+	be := battle.NewBattleEngine()
+	g := newScriptedMatch(t, be,
+		[]battle.Card{creature("Wolf", 500, 500)},
+		[]battle.Card{creature("Wall", 100, 2000, battle.KeywordGuard), creature("Bear", 100, 300)})
+	passTurn(t, be, g.ID)
+	play(t, be, g.ID, "B", "Wall")
+	play(t, be, g.ID, "B", "Bear")
+	passTurn(t, be, g.ID)
+	s := play(t, be, g.ID, "A", "Wolf")
+	mustApply(t, be, g.ID, "A", battle.Action{Type: battle.ActionChangePhase, Phase: battle.PhaseBattle})
+
+	wolf := instanceID(t, s.Player1.Field, "Wolf")
+	wall := instanceID(t, s.Player2.Field, "Wall")
+	targets := attackTargets(t, be, g.ID, "A", wolf)
+	if len(targets) != 1 || targets[0] != wall {
+		t.Fatalf("Wolf may attack %v, want only Wall", targets)
+	}
+
+	bear := instanceID(t, s.Player2.Field, "Bear")
+	_, err := be.Apply(g.ID, "A", battle.Action{Type: battle.ActionAttack, AttackerID: wolf, TargetID: bear})
+	if err == nil || !strings.Contains(err.Error(), "guard") {
+		t.Fatalf("got error %v attacking past Wall, want one about guard", err)
+	}
+}
+
+func TestStealthCannotBeTargetedUntilItAttacks(t *testing.T) {
+	be := battle.NewBattleEngine()
+	g := newScriptedMatch(t, be,
+		[]battle.Card{creature("Wolf", 500, 500)},
+		[]battle.Card{creature("Shade", 600, 2000, battle.KeywordStealth)})
+	passTurn(t, be, g.ID)
+	play(t, be, g.ID, "B", "Shade")
+	passTurn(t, be, g.ID)
+	s := play(t, be, g.ID, "A", "Wolf")
+	mustApply(t, be, g.ID, "A", battle.Action{Type: battle.ActionChangePhase, Phase: battle.PhaseBattle})
+
+	// A hidden card leaves the way to the player open
+	wolf := instanceID(t, s.Player1.Field, "Wolf")
+	shade := instanceID(t, s.Player2.Field, "Shade")
+	if targets := attackTargets(t, be, g.ID, "A", wolf); len(targets) != 1 || targets[0] != "" {
+		t.Fatalf("Wolf may attack %v, want only a direct attack", targets)
+	}
+	if _, err := be.Apply(g.ID, "A", battle.Action{Type: battle.ActionAttack, AttackerID: wolf, TargetID: shade}); err == nil {
+		t.Fatalf("Wolf attacked a card with stealth")
+	}
+
+	// Attacking reveals Shade
+	passTurn(t, be, g.ID)
+	mustApply(t, be, g.ID, "B", battle.Action{Type: battle.ActionChangePhase, Phase: battle.PhaseBattle})
+	mustApply(t, be, g.ID, "B", battle.Action{Type: battle.ActionAttack, AttackerID: shade, TargetID: wolf})
+	s = mustApply(t, be, g.ID, "A", battle.Action{Type: battle.ActionPass})
+	if s.Player2.Field[0].HasKeyword(battle.KeywordStealth) {
+		t.Fatalf("Shade kept stealth after attacking")
+	}
+}
+
+func TestLifestealHealsDamageDealt(t *testing.T) {
+	burn := battle.Card{ID: "burn", Type: battle.CardSpell, Name: "Burn", Cost: 1, Effects: []battle.CardEffect{
+		{Kind: battle.EffectDamage, Amount: 3000, Target: battle.TargetOpponent},
+	}}
+	be := battle.NewBattleEngine()
+	g := newScriptedMatch(t, be,
+		[]battle.Card{creature("Leech", 1000, 500, battle.KeywordLifesteal, battle.KeywordPiercing)},
+		[]battle.Card{burn, creature("Bear", 100, 300)})
+	play(t, be, g.ID, "A", "Leech")
+	passTurn(t, be, g.ID)
+	play(t, be, g.ID, "B", "Burn")
+	play(t, be, g.ID, "B", "Bear")
+	passTurn(t, be, g.ID)
+	s := mustApply(t, be, g.ID, "A", battle.Action{Type: battle.ActionChangePhase, Phase: battle.PhaseBattle})
+	leech := instanceID(t, s.Player1.Field, "Leech")
+
+	// Destroying Bear deals its defense and pierces for the rest
+	hp1, hp2 := s.Player1.HP, s.Player2.HP
+	mustApply(t, be, g.ID, "A", battle.Action{Type: battle.ActionAttack, AttackerID: leech, TargetID: instanceID(t, s.Player2.Field, "Bear")})
+	s = mustApply(t, be, g.ID, "B", battle.Action{Type: battle.ActionPass})
+	if s.Player2.HP != hp2-700 {
+		t.Fatalf("piercing dealt %d, want 700", hp2-s.Player2.HP)
+	}
+	if s.Player1.HP != hp1+1000 {
+		t.Fatalf("lifesteal healed %d, want 300 for Bear and 700 pierced", s.Player1.HP-hp1)
+	}
+
+	// A direct attack heals by the full attack
+	passTurn(t, be, g.ID)
+	passTurn(t, be, g.ID)
+	s = mustApply(t, be, g.ID, "A", battle.Action{Type: battle.ActionChangePhase, Phase: battle.PhaseBattle})
+	hp1 = s.Player1.HP
+	mustApply(t, be, g.ID, "A", battle.Action{Type: battle.ActionAttack, AttackerID: leech})
+	s = mustApply(t, be, g.ID, "B", battle.Action{Type: battle.ActionPass})
+	if s.Player1.HP != hp1+1000 {
+		t.Fatalf("lifesteal healed %d on a direct attack, want 1000", s.Player1.HP-hp1)
+	}
+}
@@ -0,0 +1,98 @@
+package battle
+
+// LegalActions lists the actions playerID may currently submit to Apply.
+// It is empty when the player may not act: it is not their turn, the
+// opponent holds priority, or the game is over. During the mulligan phase a
+// player who has not mulliganed gets a single mulligan without cards, which
+// keeps the hand; any selection of cards from the hand may be sent in its
+// place. While the stack is not empty the player with priority gets their
+// responses and a pass.
+// Conceding is always allowed while the game runs and is not listed.
+func (be *BattleEngine) LegalActions(gameID, playerID string) ([]Action, error) {
+	var actions []Action
+	err := be.run(gameID, func(m *match) error {
+		actions = be.legalActions(m.game, playerID)
+		return nil
+	})
+	return actions, err
+}
+
+func (be *BattleEngine) legalActions(game *GameState, playerID string) []Action {
+	player := be.getPlayer(game, playerID)
+	if game.GameOver || player == nil {
+		return nil
+	}
+
+	var actions []Action
+
+	if game.Phase == PhaseMulligan {
+		if player.MulliganDone {
+			return nil
+		}
+		return []Action{{Type: ActionMulligan}}
+	}
+
+	if len(game.Stack) > 0 {
+		if game.Priority != playerID {
+			return nil
+		}
+		for _, zone := range [][]Card{player.Hand, player.Traps} {
+			for _, card := range zone {
+				if be.checkRespond(game, player, card.InstanceID) == nil {
+					actions = append(actions, Action{Type: ActionRespond, CardID: card.InstanceID})
+				}
+			}
+		}
+		return append(actions, Action{Type: ActionPass})
+	}
+
+	if game.CurrentTurn != playerID {
+		return nil
+	}
+
+	switch game.Phase {
+	case PhaseDrawn:
+		actions = append(actions, Action{Type: ActionDraw})
+
+	case PhaseMain, PhaseMain2:
+		for _, card := range player.Hand {
+			if card.Type != CardEquipment {
+				if be.checkPlayCard(game, player, card.InstanceID, "") == nil {
+					actions = append(actions, Action{Type: ActionPlayCard, CardID: card.InstanceID})
+				}
+				continue
+			}
+			for _, target := range player.Field {
+				if be.checkPlayCard(game, player, card.InstanceID, target.InstanceID) == nil {
+					actions = append(actions, Action{Type: ActionPlayCard, CardID: card.InstanceID, TargetID: target.InstanceID})
+				}
+			}
+		}
+		if CanTransition(game.Phase, PhaseBattle) {
+			actions = append(actions, Action{Type: ActionChangePhase, Phase: PhaseBattle})
+		}
+
+	case PhaseBattle:
+		opponent := be.getOpponent(game, playerID)
+		// The empty target is a direct attack
+		targets := []string{""}
+		for _, card := range opponent.Field {
+			targets = append(targets, card.InstanceID)
+		}
+		for _, card := range player.Field {
+			for _, target := range targets {
+				if be.checkAttack(game, player, card.InstanceID, target) == nil {
+					actions = append(actions, Action{Type: ActionAttack, AttackerID: card.InstanceID, TargetID: target})
+				}
+			}
+		}
+		actions = append(actions, Action{Type: ActionChangePhase, Phase: PhaseMain2})
+	}
+
+	// Entering the end phase on its own is left out since ending the turn
+	// passes through it anyway
+	if CanTransition(game.Phase, PhaseEnd) || game.Phase == PhaseEnd {
+		actions = append(actions, Action{Type: ActionEndTurn})
+	}
+	return actions
+}
@@ -0,0 +1,239 @@
+package battle
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// DefaultArchiveLimit is how many closed matches an engine keeps by default
+const DefaultArchiveLimit = 256
+
+// MatchStatus is where a match is in its lifecycle
+type MatchStatus string
+
+const (
+	MatchActive   MatchStatus = "active"
+	MatchFinished MatchStatus = "finished"
+	MatchArchived MatchStatus = "archived"
+)
+
+// MatchInfo summarises a match for listings
+type MatchInfo struct {
+	ID           string       `json:"id"`
+	Player1ID    string       `json:"player1_id"`
+	Player2ID    string       `json:"player2_id"`
+	Status       MatchStatus  `json:"status"`
+	Rules        string       `json:"rules"`
+	TurnCount    int          `json:"turn_count"`
+	Phase        GamePhase    `json:"phase"`
+	Actions      int          `json:"actions"`
+	CreatedAt    time.Time    `json:"created_at"`
+	LastActivity time.Time    `json:"last_activity"`
+	Result       *MatchResult `json:"result,omitempty"`
+}
+
+// MatchRecord is a closed match, kept so it can still be listed and replayed
+type MatchRecord struct {
+	Info  MatchInfo  `json:"info"`
+	Setup MatchSetup `json:"setup"`
+	Log   []LogEntry `json:"log"`
+}
+
+// info summarises the game
+func (g *GameState) info() MatchInfo {
+	status := MatchActive
+	if g.GameOver {
+		status = MatchFinished
+	}
+
+	return MatchInfo{
+		ID:           g.ID,
+		Player1ID:    g.Player1.ID,
+		Player2ID:    g.Player2.ID,
+		Status:       status,
+		Rules:        g.Rules.Name,
+		TurnCount:    g.TurnCount,
+		Phase:        g.Phase,
+		Actions:      len(g.log),
+		CreatedAt:    g.createdAt,
+		LastActivity: g.lastActivity,
+		Result:       g.Result.copy(),
+	}
+}
+
+// newGameID returns a match ID not used by any live or archived match
+func (be *BattleEngine) newGameID() string {
+	for {
+		b := make([]byte, 8)
+		rand.Read(b)
+		id := "game_" + hex.EncodeToString(b)
+		if _, live := be.games[id]; live {
+			continue
+		}
+		if _, archived := be.archive[id]; archived {
+			continue
+		}
+		return id
+	}
+}
+
+// ListMatches returns every match the engine still holds, oldest first
+func (be *BattleEngine) ListMatches() []MatchInfo {
+	be.mu.RLock()
+	live := make([]*match, 0, len(be.games))
+	for _, m := range be.games {
+		live = append(live, m)
+	}
+	be.mu.RUnlock()
+
+	// Matches closed in the meantime are left out
+	matches := make([]MatchInfo, 0, len(live))
+	for _, m := range live {
+		m.do(func() { matches = append(matches, m.game.info()) })
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		if !matches[i].CreatedAt.Equal(matches[j].CreatedAt) {
+			return matches[i].CreatedAt.Before(matches[j].CreatedAt)
+		}
+		return matches[i].ID < matches[j].ID
+	})
+	return matches
+}
+
+// CloseMatch removes a finished match from the engine and archives it.
+// Matches still in progress must end first, by play or by Forfeit.
+func (be *BattleEngine) CloseMatch(gameID string) (*MatchRecord, error) {
+	var record *MatchRecord
+	var closed *match
+	err := be.run(gameID, func(m *match) error {
+		if !m.game.GameOver {
+			return fmt.Errorf("match is still in progress")
+		}
+		record = m.game.matchRecord()
+		closed = m
+		m.stop()
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	be.mu.Lock()
+	defer be.mu.Unlock()
+	if be.removeMatch(gameID, closed) {
+		be.archiveRecord(record)
+	}
+	return record, nil
+}
+
+// EvictIdle drops every match nothing has happened in for longer than ttl
+// and returns their IDs. Finished matches are archived; unfinished ones
+// are abandoned and discarded.
+func (be *BattleEngine) EvictIdle(ttl time.Duration) []string {
+	be.mu.RLock()
+	live := make(map[string]*match, len(be.games))
+	for id, m := range be.games {
+		live[id] = m
+	}
+	be.mu.RUnlock()
+
+	cutoff := be.now().Add(-ttl)
+	var evicted []string
+	for id, m := range live {
+		var idle bool
+		var record *MatchRecord
+		m.do(func() {
+			if idle = m.game.lastActivity.Before(cutoff); !idle {
+				return
+			}
+			if m.game.GameOver {
+				record = m.game.matchRecord()
+			}
+			m.stop()
+		})
+		if !idle {
+			continue
+		}
+
+		be.mu.Lock()
+		if be.removeMatch(id, m) {
+			if record != nil {
+				be.archiveRecord(record)
+			}
+			evicted = append(evicted, id)
+		}
+		be.mu.Unlock()
+	}
+	sort.Strings(evicted)
+	return evicted
+}
+
+// ArchivedMatch returns a closed match
+func (be *BattleEngine) ArchivedMatch(gameID string) (*MatchRecord, error) {
+	be.mu.RLock()
+	defer be.mu.RUnlock()
+
+	record, exists := be.archive[gameID]
+	if !exists {
+		return nil, fmt.Errorf("archived match not found")
+	}
+
+	copied := *record
+	copied.Info.Result = record.Info.Result.copy()
+	copied.Setup = record.Setup.copy()
+	copied.Log = append([]LogEntry(nil), record.Log...)
+	return &copied, nil
+}
+
+// ListArchived returns the closed matches still in the archive, oldest first
+func (be *BattleEngine) ListArchived() []MatchInfo {
+	be.mu.RLock()
+	defer be.mu.RUnlock()
+
+	matches := make([]MatchInfo, len(be.archiveOrder))
+	for i, id := range be.archiveOrder {
+		matches[i] = be.archive[id].Info
+		matches[i].Result = matches[i].Result.copy()
+	}
+	return matches
+}
+
+// SetArchiveLimit sets how many closed matches the engine keeps. The oldest
+// are dropped first; zero keeps none.
+func (be *BattleEngine) SetArchiveLimit(limit int) {
+	be.mu.Lock()
+	defer be.mu.Unlock()
+
+	be.archiveLimit = max(limit, 0)
+	be.trimArchive()
+}
+
+// matchRecord captures a finished game for the archive
+func (g *GameState) matchRecord() *MatchRecord {
+	record := &MatchRecord{
+		Info:  g.info(),
+		Setup: g.setup,
+		Log:   append([]LogEntry(nil), g.log...),
+	}
+	record.Info.Status = MatchArchived
+	return record
+}
+
+// archiveRecord files a closed match in the archive. Callers hold the
+// engine lock.
+func (be *BattleEngine) archiveRecord(record *MatchRecord) {
+	be.archive[record.Info.ID] = record
+	be.archiveOrder = append(be.archiveOrder, record.Info.ID)
+	be.trimArchive()
+}
+
+// trimArchive drops the oldest archived matches beyond the limit
+func (be *BattleEngine) trimArchive() {
+	for len(be.archiveOrder) > be.archiveLimit {
+		delete(be.archive, be.archiveOrder[0])
+		be.archiveOrder = be.archiveOrder[1:]
+	}
+}
@@ -0,0 +1,111 @@
+package battle_test
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+	"time"
+
+	"cardgame/battle"
+)
+
+func TestArchivedMatchIsACopy(t *testing.T) {
+	be := battle.NewBattleEngine()
+	g := newMatch(t, be, 7)
+	playRandom(t, be, g.ID, rand.New(rand.NewSource(7)), 20, nil)
+	if _, err := be.Forfeit(g.ID, "A", battle.ReasonConcession); err != nil {
+		t.Fatalf("forfeiting: %v", err)
+	}
+	if _, err := be.CloseMatch(g.ID); err != nil {
+		t.Fatalf("closing match: %v", err)
+	}
+
+	record, err := be.ArchivedMatch(g.ID)
+	if err != nil {
+		t.Fatalf("reading archive: %v", err)
+	}
+	want, _ := be.ArchivedMatch(g.ID)
+
+	// Nothing done to a returned record may reach the archive
+	record.Setup.Deck1[0].Name = "Tampered"
+	record.Setup.Deck1[0].Keywords = append(record.Setup.Deck1[0].Keywords, battle.KeywordGuard)
+	record.Setup.Deck2 = record.Setup.Deck2[:1]
+	record.Info.Result.Winner = "A"
+	record.Log[0].PlayerID = "Tampered"
+
+	got, _ := be.ArchivedMatch(g.ID)
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("changing a returned record changed the archive")
+	}
+	if archived := be.ListArchived(); len(archived) != 1 || archived[0].Result.Winner != "B" {
+		t.Fatalf("archive listing changed")
+	}
+}
+
+func TestEvictIdle(t *testing.T) {
+	be := battle.NewBattleEngine()
+	clock := newFakeClock(be)
+	finished := newMatch(t, be, 1)
+	abandoned := newMatch(t, be, 2)
+	if _, err := be.Forfeit(finished.ID, "A", battle.ReasonConcession); err != nil {
+		t.Fatalf("forfeiting: %v", err)
+	}
+	clock.advance(2 * time.Minute)
+	recent := newMatch(t, be, 3)
+	clock.advance(time.Minute)
+
+	evicted := be.EvictIdle(2 * time.Minute)
+	want := []string{finished.ID, abandoned.ID}
+	if want[0] > want[1] {
+		want[0], want[1] = want[1], want[0]
+	}
+	if !reflect.DeepEqual(evicted, want) {
+		t.Fatalf("evicted %v, want %v", evicted, want)
+	}
+
+	// Finished matches are archived, unfinished ones discarded
+	if _, err := be.ArchivedMatch(finished.ID); err != nil {
+		t.Fatalf("finished match was not archived: %v", err)
+	}
+	if _, err := be.ArchivedMatch(abandoned.ID); err == nil {
+		t.Fatalf("abandoned match was archived")
+	}
+	for _, id := range want {
+		if _, err := be.GetGameState(id); err == nil {
+			t.Fatalf("evicted match %s is still live", id)
+		}
+	}
+	if live := be.ListMatches(); len(live) != 1 || live[0].ID != recent.ID {
+		t.Fatalf("got live matches %v, want only the recent one", live)
+	}
+
+	// Any action keeps a match alive
+	clock.advance(time.Minute)
+	mustApply(t, be, recent.ID, "A", battle.Action{Type: battle.ActionMulligan})
+	clock.advance(90 * time.Second)
+	if evicted := be.EvictIdle(2 * time.Minute); len(evicted) != 0 {
+		t.Fatalf("evicted %v after recent activity", evicted)
+	}
+}
+
+func TestArchiveLimitDropsOldest(t *testing.T) {
+	be := battle.NewBattleEngine()
+	var ids []string
+	for i := 0; i < 3; i++ {
+		g := newMatch(t, be, int64(i))
+		be.Forfeit(g.ID, "A", battle.ReasonConcession)
+		if _, err := be.CloseMatch(g.ID); err != nil {
+			t.Fatalf("closing match: %v", err)
+		}
+		ids = append(ids, g.ID)
+	}
+
+	be.SetArchiveLimit(2)
+	archived := be.ListArchived()
+	if len(archived) != 2 || archived[0].ID != ids[1] || archived[1].ID != ids[2] {
+		t.Fatalf("got archive %v, want the two newest matches", archived)
+	}
+	if _, err := be.ArchivedMatch(ids[0]); err == nil {
+		t.Fatalf("oldest match still archived")
+	}
+}
@@ -0,0 +1,107 @@
+package battle
+
+import (
+	"fmt"
+	"time"
+)
+
+// LogEntry records one accepted action together with the events it caused
+type LogEntry struct {
+	Seq      int     `json:"seq"`
+	PlayerID string  `json:"player_id"`
+	Action   Action  `json:"action"`
+	Events   []Event `json:"events,omitempty"`
+	Result   string  `json:"result"`
+	// Elapsed is the time the action took on the running clock
+	Elapsed time.Duration `json:"elapsed,omitempty"`
+	// StateHash is the Hash of the game right after the action
+	StateHash string `json:"state_hash,omitempty"`
+}
+
+// MatchSetup holds everything needed to recreate a match before its first action
+type MatchSetup struct {
+	GameID    string  `json:"game_id"`
+	Player1ID string  `json:"player1_id"`
+	Player2ID string  `json:"player2_id"`
+	Deck1     []Card  `json:"deck1"`
+	Deck2     []Card  `json:"deck2"`
+	Rules     RuleSet `json:"rules"`
+	Seed      int64   `json:"seed"`
+}
+
+// copy returns the setup with its own copy of both decks
+func (s MatchSetup) copy() MatchSetup {
+	s.Deck1 = copyCards(s.Deck1)
+	s.Deck2 = copyCards(s.Deck2)
+	return s
+}
+
+// GetActionLog returns every action accepted so far in a game
+func (be *BattleEngine) GetActionLog(gameID string) ([]LogEntry, error) {
+	var entries []LogEntry
+	err := be.run(gameID, func(m *match) error {
+		entries = make([]LogEntry, len(m.game.log))
+		copy(entries, m.game.log)
+		return nil
+	})
+	return entries, err
+}
+
+// GetMatchSetup returns the initial setup a game was created from
+func (be *BattleEngine) GetMatchSetup(gameID string) (*MatchSetup, error) {
+	var setup MatchSetup
+	err := be.run(gameID, func(m *match) error {
+		setup = m.game.setup.copy()
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &setup, nil
+}
+
+// Replay rebuilds the game state reached after the first steps entries of
+// entries. A negative steps replays the whole log. Entries carrying a state
+// hash are checked against it, so a log that does not reproduce the game it
+// was recorded from is caught at the first action that differs.
+func Replay(setup MatchSetup, entries []LogEntry, steps int) (*GameState, error) {
+	if steps < 0 || steps > len(entries) {
+		steps = len(entries)
+	}
+
+	// The game never leaves this function until it is handed back, so it
+	// is played directly rather than on a match goroutine
+	engine := NewBattleEngine()
+	game, err := engine.newGame(setup)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, entry := range entries[:steps] {
+		result, err := engine.applyAction(game, entry.PlayerID, entry.Action, entry.Elapsed)
+		if err != nil {
+			return nil, fmt.Errorf("replay diverged at seq %d: %v", entry.Seq, err)
+		}
+		if entry.StateHash != "" && entry.StateHash != result.StateHash {
+			return nil, fmt.Errorf("replay diverged at seq %d: state hash mismatch", entry.Seq)
+		}
+	}
+
+	return game, nil
+}
+
+// record appends an accepted action and the events it caused to the game's log
+func (be *BattleEngine) record(game *GameState, playerID string, action Action, elapsed time.Duration) LogEntry {
+	entry := LogEntry{
+		Seq:      len(game.log) + 1,
+		PlayerID: playerID,
+		Action:   action,
+		Events:   game.events.drainHistory(),
+		Result:   game.LastAction,
+		Elapsed:  elapsed,
+
+		StateHash: game.Hash(),
+	}
+	game.log = append(game.log, entry)
+	return entry
+}
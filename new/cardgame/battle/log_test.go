@@ -0,0 +1,141 @@
+package battle_test
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+	"time"
+
+	"cardgame/battle"
+)
+
+// recordedMatch plays a random timed match and returns its setup, log and
+// final state
+func recordedMatch(t *testing.T, seed int64, steps int) (battle.MatchSetup, []battle.LogEntry, *battle.GameState) {
+	t.Helper()
+	be := battle.NewBattleEngine()
+	g := newMatchWithRules(t, be, timedRules(), seed)
+	final := playRandom(t, be, g.ID, rand.New(rand.NewSource(seed)), steps, nil)
+
+	setup, err := be.GetMatchSetup(g.ID)
+	if err != nil {
+		t.Fatalf("reading setup: %v", err)
+	}
+	entries, err := be.GetActionLog(g.ID)
+	if err != nil {
+		t.Fatalf("reading log: %v", err)
+	}
+	return *setup, entries, final
+}
+
+func TestSeededMatchesAreDeterministic(t *testing.T) {
+	// Both engines see the same time, so the clocks run alike
+	now := time.Unix(0, 0)
+	first := battle.NewBattleEngine()
+	second := battle.NewBattleEngine()
+	first.SetClock(func() time.Time { return now })
+	second.SetClock(func() time.Time { return now })
+
+	a := newMatchWithRules(t, first, timedRules(), 42)
+	b := newMatchWithRules(t, second, timedRules(), 42)
+	if !sameCards(a.Player1.Deck, b.Player1.Deck) || !sameCards(a.Player2.Hand, b.Player2.Hand) {
+		t.Fatalf("the same seed dealt different cards")
+	}
+
+	other := newMatch(t, battle.NewBattleEngine(), 43)
+	if sameCards(a.Player1.Deck, other.Player1.Deck) {
+		t.Fatalf("different seeds dealt the same deck")
+	}
+
+	// The same moves lead to the same states
+	rng := rand.New(rand.NewSource(42))
+	for i := 0; i < 200; i++ {
+		sa, _ := first.GetGameState(a.ID)
+		sb, _ := second.GetGameState(b.ID)
+		sb.ID = sa.ID // game IDs are not decided by the seed
+		if sa.Hash() != sb.Hash() {
+			t.Fatalf("step %d: states diverged", i)
+		}
+		if sa.GameOver {
+			break
+		}
+
+		player := actor(sa)
+		legal, _ := first.LegalActions(a.ID, player)
+		action := legal[rng.Intn(len(legal))]
+		if _, err := first.Apply(a.ID, player, action); err != nil {
+			t.Fatalf("step %d: %v", i, err)
+		}
+		if _, err := second.Apply(b.ID, player, action); err != nil {
+			t.Fatalf("step %d: second engine rejected %v: %v", i, action, err)
+		}
+	}
+}
+
+func sameCards(a, b []battle.Card) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].InstanceID != b[i].InstanceID {
+			return false
+		}
+	}
+	return true
+}
+
+func TestReplayRebuildsFinalState(t *testing.T) {
+	for seed := int64(1); seed <= 3; seed++ {
+		setup, entries, final := recordedMatch(t, seed, 300)
+
+		replayed, err := battle.Replay(setup, entries, -1)
+		if err != nil {
+			t.Fatalf("seed %d: %v", seed, err)
+		}
+		if replayed.Hash() != final.Hash() {
+			t.Fatalf("seed %d: replay reached a different state", seed)
+		}
+
+		// Replaying part of the log stops where it was recorded
+		halfway := len(entries) / 2
+		partial, err := battle.Replay(setup, entries, halfway)
+		if err != nil {
+			t.Fatalf("seed %d: %v", seed, err)
+		}
+		if partial.Hash() != entries[halfway-1].StateHash {
+			t.Fatalf("seed %d: partial replay reached a different state", seed)
+		}
+	}
+}
+
+func TestReplayRejectsTamperedLog(t *testing.T) {
+	setup, entries, _ := recordedMatch(t, 8, 100)
+	at := len(entries) / 2
+
+	tampered := func(change func(entries []battle.LogEntry)) []battle.LogEntry {
+		copied := append([]battle.LogEntry(nil), entries...)
+		change(copied)
+		return copied
+	}
+	tests := map[string][]battle.LogEntry{
+		"state hash": tampered(func(e []battle.LogEntry) { e[at].StateHash = entries[at-1].StateHash }),
+		// The mulligan clock runs during the first action
+		"elapsed": tampered(func(e []battle.LogEntry) { e[0].Elapsed += time.Second }),
+	}
+	for name, log := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := battle.Replay(setup, log, -1)
+			if err == nil || !strings.Contains(err.Error(), "state hash mismatch") {
+				t.Fatalf("got error %v, want a state hash mismatch", err)
+			}
+		})
+	}
+
+	t.Run("seed", func(t *testing.T) {
+		other := setup
+		other.Seed++
+		if _, err := battle.Replay(other, entries, -1); err == nil {
+			t.Fatalf("replay with another seed succeeded")
+		}
+	})
+}
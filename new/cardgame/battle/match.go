@@ -0,0 +1,113 @@
+package battle
+
+import (
+	"context"
+	"fmt"
+)
+
+// match owns one live game. Its goroutine is the only one that ever touches
+// the game: every engine call on the match is sent to it as a command, so
+// commands run one at a time in the order they arrive, and matches on the
+// same engine never wait on each other.
+type match struct {
+	game     *GameState
+	commands chan func()
+	ctx      context.Context
+	stop     context.CancelFunc
+	done     chan struct{}
+}
+
+// startMatch starts the goroutine of a new match. It runs until stop is
+// called or ctx is cancelled.
+func startMatch(ctx context.Context, game *GameState) *match {
+	ctx, stop := context.WithCancel(ctx)
+	m := &match{
+		game:     game,
+		commands: make(chan func()),
+		ctx:      ctx,
+		stop:     stop,
+		done:     make(chan struct{}),
+	}
+	go m.loop()
+	return m
+}
+
+// loop runs commands until the match is stopped, then ends its subscriptions
+func (m *match) loop() {
+	defer close(m.done)
+
+	for m.ctx.Err() == nil {
+		select {
+		case command := <-m.commands:
+			command()
+		case <-m.ctx.Done():
+		}
+	}
+	closeSubscriptions(m.game)
+}
+
+// do runs fn on the match goroutine and waits for it to finish. Once the
+// match has stopped nothing runs and the game counts as gone.
+func (m *match) do(fn func()) error {
+	finished := make(chan struct{})
+	command := func() {
+		defer close(finished)
+		fn()
+	}
+
+	select {
+	case m.commands <- command:
+		<-finished
+		return nil
+	case <-m.ctx.Done():
+		return fmt.Errorf("game not found")
+	}
+}
+
+// run executes fn on the goroutine of a live match and waits for it
+func (be *BattleEngine) run(gameID string, fn func(m *match) error) error {
+	be.mu.RLock()
+	m, exists := be.games[gameID]
+	be.mu.RUnlock()
+	if !exists {
+		return fmt.Errorf("game not found")
+	}
+
+	var err error
+	if doErr := m.do(func() { err = fn(m) }); doErr != nil {
+		return doErr
+	}
+	return err
+}
+
+// removeMatch forgets a stopped match, unless it was already removed.
+// Callers hold the engine lock.
+func (be *BattleEngine) removeMatch(gameID string, m *match) bool {
+	if be.games[gameID] != m {
+		return false
+	}
+	delete(be.games, gameID)
+	return true
+}
+
+// Shutdown stops every match and refuses new ones. It waits for the match
+// goroutines to exit, giving up once ctx is done. Matches are not archived.
+func (be *BattleEngine) Shutdown(ctx context.Context) error {
+	be.mu.Lock()
+	matches := make([]*match, 0, len(be.games))
+	for id, m := range be.games {
+		matches = append(matches, m)
+		delete(be.games, id)
+	}
+	be.stop()
+	be.mu.Unlock()
+
+	for _, m := range matches {
+		select {
+		case <-m.done:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
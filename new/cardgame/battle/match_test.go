@@ -0,0 +1,266 @@
+package battle_test
+
+import (
+	"context"
+	"math/rand"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"cardgame/battle"
+	"cardgame/game"
+)
+
+// hammer has workers goroutines make random moves in a game until it ends,
+// tries runs out or the engine stops taking them. Moves picked from a stale
+// state may be refused; that is expected.
+func hammer(be *battle.BattleEngine, gameID string, workers, tries int, wg *sync.WaitGroup) {
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(seed int64) {
+			defer wg.Done()
+			rng := rand.New(rand.NewSource(seed))
+			for i := 0; i < tries; i++ {
+				s, err := be.GetGameState(gameID)
+				if err != nil || s.GameOver {
+					return
+				}
+				player := actor(s)
+				legal, err := be.LegalActions(gameID, player)
+				if err != nil || len(legal) == 0 {
+					continue
+				}
+				be.Apply(gameID, player, legal[rng.Intn(len(legal))])
+			}
+		}(int64(w))
+	}
+}
+
+func TestConcurrentApply(t *testing.T) {
+	be := battle.NewBattleEngine()
+	games := make([]*battle.GameState, 4)
+	for i := range games {
+		games[i] = newMatch(t, be, int64(i))
+	}
+
+	var movers, readers sync.WaitGroup
+	seen := make([][]int, len(games))
+	for i, g := range games {
+		sub, err := be.Subscribe(g.ID, 10000)
+		if err != nil {
+			t.Fatalf("subscribing: %v", err)
+		}
+		readers.Add(1)
+		go func(i int) {
+			defer readers.Done()
+			for change := range sub.Updates() {
+				seen[i] = append(seen[i], change.Seq)
+			}
+		}(i)
+		hammer(be, g.ID, 8, 100, &movers)
+	}
+	movers.Wait()
+
+	// End unfinished games so they can be closed
+	for _, g := range games {
+		for {
+			s, _ := be.GetGameState(g.ID)
+			if s.GameOver {
+				break
+			}
+			if _, err := be.Forfeit(g.ID, "A", battle.ReasonConcession); err == nil {
+				break
+			}
+		}
+	}
+
+	for i, g := range games {
+		final, err := be.GetGameState(g.ID)
+		if err != nil {
+			t.Fatalf("reading state: %v", err)
+		}
+		setup, _ := be.GetMatchSetup(g.ID)
+		entries, _ := be.GetActionLog(g.ID)
+		for n, entry := range entries {
+			if entry.Seq != n+1 {
+				t.Fatalf("game %d: log entry %d has seq %d", i, n, entry.Seq)
+			}
+		}
+
+		// Commands ran one at a time, so the log replays to the same game
+		replayed, err := battle.Replay(*setup, entries, -1)
+		if err != nil {
+			t.Fatalf("game %d: %v", i, err)
+		}
+		if replayed.Hash() != final.Hash() {
+			t.Fatalf("game %d: replay reached a different state", i)
+		}
+		if _, err := be.CloseMatch(g.ID); err != nil {
+			t.Fatalf("closing match: %v", err)
+		}
+	}
+	readers.Wait()
+
+	for i, seqs := range seen {
+		for n := 1; n < len(seqs); n++ {
+			if seqs[n] <= seqs[n-1] {
+				t.Fatalf("game %d: subscriber saw seq %d after %d", i, seqs[n], seqs[n-1])
+			}
+		}
+	}
+}
+
+func TestShutdownWithCommandsInFlight(t *testing.T) {
+	be := battle.NewBattleEngine()
+	var wg sync.WaitGroup
+	var subs []*battle.Subscription
+	for i := 0; i < 4; i++ {
+		g := newMatch(t, be, int64(i))
+		sub, err := be.Subscribe(g.ID, 1)
+		if err != nil {
+			t.Fatalf("subscribing: %v", err)
+		}
+		subs = append(subs, sub)
+		hammer(be, g.ID, 8, 1000, &wg)
+	}
+
+	time.Sleep(10 * time.Millisecond)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := be.Shutdown(ctx); err != nil {
+		t.Fatalf("shutting down: %v", err)
+	}
+
+	// Every caller gets an answer once the matches are gone
+	finished := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(finished)
+	}()
+	select {
+	case <-finished:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("engine calls still blocked after shutdown")
+	}
+
+	for _, sub := range subs {
+		for range sub.Updates() {
+		}
+		sub.Unsubscribe()
+	}
+	decks := game.NewDeckBuilder()
+	if _, err := be.CreateMatch("A", "B", decks.CreateEgyptianDeck(), decks.CreateGreekDeck(), battle.StandardRules()); err == nil {
+		t.Fatalf("created a match after shutdown")
+	}
+}
+
+func TestSubscriptionAfterCloseMatch(t *testing.T) {
+	be := battle.NewBattleEngine()
+	g := newMatch(t, be, 4)
+	sub, err := be.Subscribe(g.ID, 0)
+	if err != nil {
+		t.Fatalf("subscribing: %v", err)
+	}
+	if _, err := be.Forfeit(g.ID, "B", battle.ReasonConcession); err != nil {
+		t.Fatalf("forfeiting: %v", err)
+	}
+	if _, err := be.CloseMatch(g.ID); err != nil {
+		t.Fatalf("closing match: %v", err)
+	}
+
+	var last battle.StateChange
+	for change := range sub.Updates() {
+		last = change
+	}
+	if last.State == nil || !last.State.GameOver {
+		t.Fatalf("subscriber missed the end of the match")
+	}
+	if err := sub.Err(); err == nil || !strings.Contains(err.Error(), "match closed") {
+		t.Fatalf("got error %v, want match closed", err)
+	}
+
+	// Neither call may block on the stopped match
+	done := make(chan struct{})
+	go func() {
+		sub.Unsubscribe()
+		sub.Unsubscribe()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Unsubscribe blocked after CloseMatch")
+	}
+
+	if _, err := be.Subscribe(g.ID, 0); err == nil {
+		t.Fatalf("subscribed to a closed match")
+	}
+}
+
+func TestSlowSubscriberIsDropped(t *testing.T) {
+	be := battle.NewBattleEngine()
+	g := newMatch(t, be, 6)
+	slow, err := be.Subscribe(g.ID, 2)
+	if err != nil {
+		t.Fatalf("subscribing: %v", err)
+	}
+	fast, err := be.Subscribe(g.ID, 0)
+	if err != nil {
+		t.Fatalf("subscribing: %v", err)
+	}
+	defer fast.Unsubscribe()
+
+	// The engine must not wait for the slow subscriber to read
+	final := playRandom(t, be, g.ID, rand.New(rand.NewSource(6)), 10, nil)
+
+	received := 0
+	for range slow.Updates() {
+		received++
+	}
+	if received != 2 {
+		t.Fatalf("slow subscriber got %d changes, want its buffer of 2", received)
+	}
+	if err := slow.Err(); err == nil || !strings.Contains(err.Error(), "fell more than 2") {
+		t.Fatalf("got error %v, want one about falling behind", err)
+	}
+	slow.Unsubscribe()
+
+	// Others keep receiving every change
+	var last battle.StateChange
+	for len(fast.Updates()) > 0 {
+		last = <-fast.Updates()
+	}
+	if last.Seq != final.Version || fast.Err() != nil {
+		t.Fatalf("fast subscriber stopped at seq %d, want %d", last.Seq, final.Version)
+	}
+}
+
+func TestStateChangesCarryLegalActions(t *testing.T) {
+	be := battle.NewBattleEngine()
+	g := newMatch(t, be, 12)
+	sub, err := be.Subscribe(g.ID, 1000)
+	if err != nil {
+		t.Fatalf("subscribing: %v", err)
+	}
+	defer sub.Unsubscribe()
+
+	playRandom(t, be, g.ID, rand.New(rand.NewSource(12)), 100, func(s *battle.GameState) {
+		change := <-sub.Updates()
+		if change.Seq != s.Version {
+			t.Fatalf("got change %d, want %d", change.Seq, s.Version)
+		}
+		for _, player := range []string{"A", "B"} {
+			want, _ := be.LegalActions(g.ID, player)
+			got := change.Legal[player]
+			if len(got) != len(want) {
+				t.Fatalf("version %d: %s got %d legal actions, want %d", s.Version, player, len(got), len(want))
+			}
+			for i := range want {
+				if !got[i].Equal(want[i]) {
+					t.Fatalf("version %d: %s got %v, want %v", s.Version, player, got[i], want[i])
+				}
+			}
+		}
+	})
+}
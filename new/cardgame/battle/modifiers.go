@@ -0,0 +1,174 @@
+package battle
+
+import "fmt"
+
+// ModifierKind identifies what a modifier does to the field card carrying it
+type ModifierKind string
+
+const (
+	// ModifierBuff raises the card's attack and defense
+	ModifierBuff ModifierKind = "buff"
+	// ModifierDebuff lowers the card's attack and defense
+	ModifierDebuff ModifierKind = "debuff"
+	// ModifierPoison deals Amount damage to the card at the end of each of
+	// its controller's turns
+	ModifierPoison ModifierKind = "poison"
+	// ModifierStun keeps the card from attacking
+	ModifierStun ModifierKind = "stun"
+	// ModifierShield prevents the next damage the card would take, or its
+	// destruction in combat, and is then used up. It does not save a card
+	// whose defense drops to zero because it lost buffs or auras.
+	ModifierShield ModifierKind = "shield"
+)
+
+// Modifier is a status effect on a field card. Turns counts the ends of
+// its controller's turns the modifier lasts; zero lasts until the card
+// leaves the field.
+type Modifier struct {
+	Kind    ModifierKind `json:"kind"`
+	Attack  int          `json:"attack,omitempty"`
+	Defense int          `json:"defense,omitempty"`
+	Amount  int          `json:"amount,omitempty"`
+	Turns   int          `json:"turns,omitempty"`
+}
+
+// String returns a short human readable form of the modifier
+func (m Modifier) String() string {
+	var s string
+	switch m.Kind {
+	case ModifierBuff:
+		s = fmt.Sprintf("+%d/+%d", m.Attack, m.Defense)
+	case ModifierDebuff:
+		s = fmt.Sprintf("-%d/-%d", m.Attack, m.Defense)
+	case ModifierPoison:
+		s = fmt.Sprintf("poison %d", m.Amount)
+	default:
+		s = string(m.Kind)
+	}
+
+	if m.Turns > 0 {
+		s += fmt.Sprintf(" (%dt)", m.Turns)
+	}
+	return s
+}
+
+// HasModifier reports whether the card carries a modifier of the given kind
+func (c Card) HasModifier(kind ModifierKind) bool {
+	for _, m := range c.Modifiers {
+		if m.Kind == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// consumeShield uses up a shield on the card, reporting whether it had one
+func (c *Card) consumeShield() bool {
+	for i, m := range c.Modifiers {
+		if m.Kind == ModifierShield {
+			c.Modifiers = append(c.Modifiers[:i:i], c.Modifiers[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// enterField records the card's printed stats and keywords as its base
+// ones. Every later change to its attack and defense is derived from them.
+func (c *Card) enterField() {
+	c.BaseAttack = c.Attack
+	c.BaseDefense = c.Defense
+	c.BaseKeywords = append([]Keyword(nil), c.Keywords...)
+	c.Damage = 0
+	c.Modifiers = nil
+}
+
+// leaveField restores the card's printed stats and keywords and drops
+// everything it picked up on the field
+func (c *Card) leaveField() {
+	c.Attack = c.BaseAttack
+	c.Defense = c.BaseDefense
+	c.Keywords = append([]Keyword(nil), c.BaseKeywords...)
+	c.Damage = 0
+	c.Modifiers = nil
+	c.Attached = nil
+}
+
+// recomputeStats sets the attack and defense of every field card from its
+// base stats, attached equipment, modifiers and archetype auras, less any
+// damage it has taken
+func (be *BattleEngine) recomputeStats(game *GameState) {
+	for _, player := range []*Player{game.Player1, game.Player2} {
+		for archetype := range player.ArchetypeBonus {
+			delete(player.ArchetypeBonus, archetype)
+		}
+
+		for i := range player.Field {
+			card := &player.Field[i]
+			attack, defense := card.BaseAttack, card.BaseDefense
+
+			for _, equipment := range card.Attached {
+				attack += equipment.Attack
+				defense += equipment.Defense
+			}
+			for _, m := range card.Modifiers {
+				switch m.Kind {
+				case ModifierBuff:
+					attack += m.Attack
+					defense += m.Defense
+				case ModifierDebuff:
+					attack -= m.Attack
+					defense -= m.Defense
+				}
+			}
+
+			auraAttack, auraDefense, bonus := archetypeAura(game.Rules, player, *card)
+			if bonus > 0 {
+				player.ArchetypeBonus[card.Archetype] = bonus
+			}
+
+			card.Attack = max(attack+auraAttack, 0)
+			card.Defense = defense + auraDefense - card.Damage
+		}
+	}
+}
+
+// applyModifier puts a modifier on a field card
+func (be *BattleEngine) applyModifier(game *GameState, card *Card, modifier Modifier) {
+	card.Modifiers = append(card.Modifiers, modifier)
+	be.recomputeStats(game)
+}
+
+// tickModifiers runs the end of turn step for the modifiers on player's
+// field: poison deals its damage, then every timed modifier counts down
+// and expires once it runs out
+func (be *BattleEngine) tickModifiers(game *GameState, player *Player) {
+	// Poison that expires this turn still deals its damage
+	poison := make([]int, len(player.Field))
+	for i := range player.Field {
+		card := &player.Field[i]
+		var kept []Modifier
+		for _, m := range card.Modifiers {
+			if m.Kind == ModifierPoison {
+				poison[i] += m.Amount
+			}
+			if m.Turns > 0 {
+				m.Turns--
+				if m.Turns == 0 {
+					continue
+				}
+			}
+			kept = append(kept, m)
+		}
+		card.Modifiers = kept
+	}
+
+	be.recomputeStats(game)
+
+	for i, amount := range poison {
+		if amount > 0 {
+			be.damageCard(player, i, amount)
+		}
+	}
+	be.removeDestroyed(game, player)
+}
@@ -0,0 +1,51 @@
+package battle
+
+import "fmt"
+
+// mulligan shuffles the chosen cards from player's opening hand back into
+// their deck and draws as many replacements. Each player mulligans once;
+// the first turn starts as soon as both have.
+func (be *BattleEngine) mulligan(game *GameState, player *Player, cardIDs []string) error {
+	if player.MulliganDone {
+		return fmt.Errorf("you have already mulliganed")
+	}
+
+	chosen := make(map[string]bool, len(cardIDs))
+	for _, cardID := range cardIDs {
+		if chosen[cardID] {
+			return fmt.Errorf("card %s chosen twice", cardID)
+		}
+		if findCard(player.Hand, cardID) == -1 {
+			return fmt.Errorf("card %s not in hand", cardID)
+		}
+		chosen[cardID] = true
+	}
+
+	if len(cardIDs) > 0 {
+		var kept []Card
+		for _, card := range player.Hand {
+			if chosen[card.InstanceID] {
+				player.Deck = append(player.Deck, card)
+			} else {
+				kept = append(kept, card)
+			}
+		}
+		player.Hand = kept
+		player.Deck = shuffleDeck(game.rng, player.Deck)
+		drawCards(game.Rules, player, len(cardIDs))
+	}
+	player.MulliganDone = true
+
+	if len(cardIDs) == 0 {
+		game.LastAction = fmt.Sprintf("%s kept their hand", player.ID)
+	} else {
+		game.LastAction = fmt.Sprintf("%s mulliganed %d cards", player.ID, len(cardIDs))
+	}
+
+	// The first turn gets its full time, whatever the mulligan took
+	if game.Player1.MulliganDone && game.Player2.MulliganDone {
+		game.Phase = PhaseMain
+		game.TurnTimeLeft = game.Rules.TurnTime
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+package battle_test
+
+import (
+	"sort"
+	"testing"
+
+	"cardgame/battle"
+)
+
+// instanceIDs returns the sorted instance IDs of cards
+func instanceIDs(cards ...[]battle.Card) []string {
+	var ids []string
+	for _, zone := range cards {
+		for _, card := range zone {
+			ids = append(ids, card.InstanceID)
+		}
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+func TestMulliganRedrawsChosenCards(t *testing.T) {
+	be := battle.NewBattleEngine()
+	g := newMatch(t, be, 8)
+	if g.Phase != battle.PhaseMulligan {
+		t.Fatalf("standard match opened in phase %s", g.Phase)
+	}
+	before := instanceIDs(g.Player1.Hand, g.Player1.Deck)
+
+	returned := []string{g.Player1.Hand[0].InstanceID, g.Player1.Hand[2].InstanceID}
+	s := mustApply(t, be, g.ID, "A", battle.Action{Type: battle.ActionMulligan, CardIDs: returned})
+	if len(s.Player1.Hand) != len(g.Player1.Hand) || len(s.Player1.Deck) != len(g.Player1.Deck) {
+		t.Fatalf("mulligan changed the hand or deck size")
+	}
+	if got := instanceIDs(s.Player1.Hand, s.Player1.Deck); len(got) != len(before) {
+		t.Fatalf("mulligan lost cards")
+	} else {
+		for i := range got {
+			if got[i] != before[i] {
+				t.Fatalf("mulligan changed which cards A owns")
+			}
+		}
+	}
+	// Kept cards stay in hand, in order, ahead of the new ones
+	kept := []battle.Card{g.Player1.Hand[1]}
+	kept = append(kept, g.Player1.Hand[3:]...)
+	for i, card := range kept {
+		if s.Player1.Hand[i].InstanceID != card.InstanceID {
+			t.Fatalf("kept card %s moved", card.Name)
+		}
+	}
+
+	// Each player decides once, and the match waits for both
+	if !s.Player1.MulliganDone || s.Phase != battle.PhaseMulligan {
+		t.Fatalf("mulligan phase ended before B decided")
+	}
+	if _, err := be.Apply(g.ID, "A", battle.Action{Type: battle.ActionMulligan}); err == nil {
+		t.Fatalf("A mulliganed twice")
+	}
+	if legal, _ := be.LegalActions(g.ID, "A"); len(legal) != 0 {
+		t.Fatalf("A may still %v", legal)
+	}
+	if _, err := be.Apply(g.ID, "A", battle.Action{Type: battle.ActionEndTurn}); err == nil {
+		t.Fatalf("A ended the turn during the mulligan")
+	}
+
+	s = mustApply(t, be, g.ID, "B", battle.Action{Type: battle.ActionMulligan})
+	if s.Phase != battle.PhaseMain || s.CurrentTurn != "A" {
+		t.Fatalf("got phase %s on %s's turn, want A's first main phase", s.Phase, s.CurrentTurn)
+	}
+	for i, card := range s.Player2.Hand {
+		if card.InstanceID != g.Player2.Hand[i].InstanceID {
+			t.Fatalf("keeping changed B's hand")
+		}
+	}
+	if _, err := be.Apply(g.ID, "B", battle.Action{Type: battle.ActionMulligan}); err == nil {
+		t.Fatalf("mulliganed after the first turn started")
+	}
+}
+
+func TestMulliganRejectsBadChoices(t *testing.T) {
+	be := battle.NewBattleEngine()
+	g := newMatch(t, be, 9)
+	card := g.Player1.Hand[0].InstanceID
+
+	for name, cardIDs := range map[string][]string{
+		"a card twice":        {card, card},
+		"a card not in hand":  {g.Player1.Deck[0].InstanceID},
+		"the opponent's card": {g.Player2.Hand[0].InstanceID},
+	} {
+		if _, err := be.Apply(g.ID, "A", battle.Action{Type: battle.ActionMulligan, CardIDs: cardIDs}); err == nil {
+			t.Fatalf("mulliganed %s", name)
+		}
+	}
+	if s := state(t, be, g.ID); s.Player1.MulliganDone || s.Version != g.Version {
+		t.Fatalf("a refused mulligan changed the game")
+	}
+}
+
+func TestNoMulliganWithoutTheRule(t *testing.T) {
+	be := battle.NewBattleEngine()
+	g := newMatchWithRules(t, be, battle.ClassicRules(), 10)
+	if g.Phase == battle.PhaseMulligan {
+		t.Fatalf("classic match opened with a mulligan")
+	}
+	if _, err := be.Apply(g.ID, g.CurrentTurn, battle.Action{Type: battle.ActionMulligan}); err == nil {
+		t.Fatalf("mulliganed without the rule")
+	}
+}
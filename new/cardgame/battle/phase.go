@@ -0,0 +1,118 @@
+package battle
+
+import "fmt"
+
+// phaseTransitions lists the phases a player may move to from each phase.
+// A turn runs draw → main → battle → main2 → end; battle and both main
+// phases may skip straight to end. The mulligan phase cannot be left by
+// changing phase; it ends once both players have mulliganed.
+var phaseTransitions = map[GamePhase][]GamePhase{
+	PhaseMulligan: {},
+	PhaseDrawn:    {PhaseMain},
+	PhaseMain:     {PhaseBattle, PhaseEnd},
+	PhaseBattle:   {PhaseMain2, PhaseEnd},
+	PhaseMain2:    {PhaseEnd},
+	PhaseEnd:      {},
+}
+
+// UnknownPhaseError is returned when an action names a phase the engine does not define
+type UnknownPhaseError struct {
+	Phase GamePhase
+}
+
+func (e *UnknownPhaseError) Error() string {
+	return fmt.Sprintf("unknown phase %q", e.Phase)
+}
+
+// PhaseTransitionError is returned when the turn structure does not allow moving between two phases
+type PhaseTransitionError struct {
+	From GamePhase
+	To   GamePhase
+}
+
+func (e *PhaseTransitionError) Error() string {
+	return fmt.Sprintf("cannot move from %s phase to %s phase", e.From, e.To)
+}
+
+// Valid reports whether the phase is part of the turn structure
+func (p GamePhase) Valid() bool {
+	_, ok := phaseTransitions[p]
+	return ok
+}
+
+// IsMain reports whether cards can be played from hand in the phase
+func (p GamePhase) IsMain() bool {
+	return p == PhaseMain || p == PhaseMain2
+}
+
+// CanTransition reports whether a turn may move directly from one phase to another
+func CanTransition(from, to GamePhase) bool {
+	for _, next := range phaseTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// checkTransition reports why the game cannot move to phase, if it cannot
+func checkTransition(game *GameState, phase GamePhase) error {
+	if !phase.Valid() {
+		return &UnknownPhaseError{Phase: phase}
+	}
+	if !CanTransition(game.Phase, phase) {
+		return &PhaseTransitionError{From: game.Phase, To: phase}
+	}
+	return nil
+}
+
+// changePhase moves the current turn to another phase
+func (be *BattleEngine) changePhase(game *GameState, phase GamePhase) error {
+	if err := checkTransition(game, phase); err != nil {
+		return err
+	}
+
+	game.Phase = phase
+	game.LastAction = fmt.Sprintf("%s entered %s phase", game.CurrentTurn, phase)
+	return nil
+}
+
+// endTurn runs the end of turn step for player and starts the opponent's turn
+func (be *BattleEngine) endTurn(game *GameState, player *Player) error {
+	if game.Phase != PhaseEnd {
+		if err := checkTransition(game, PhaseEnd); err != nil {
+			return err
+		}
+		game.Phase = PhaseEnd
+	}
+
+	be.emit(game, Event{Type: EventTurnEnded, PlayerID: player.ID})
+
+	// Ready the player's cards for their next turn
+	for i := range player.Field {
+		player.Field[i].HasAttacked = false
+	}
+	be.tickModifiers(game, player)
+
+	// Switch turn
+	next := be.getOpponent(game, player.ID)
+	game.CurrentTurn = next.ID
+	game.TurnCount++
+
+	be.startTurn(game, next)
+
+	game.LastAction = fmt.Sprintf("%s ended turn", player.ID)
+	return nil
+}
+
+// startTurn runs the start of turn step: the player's mana grows and
+// refills, and the turn opens in the draw phase
+func (be *BattleEngine) startTurn(game *GameState, player *Player) {
+	game.Phase = PhaseDrawn
+	game.TurnTimeLeft = game.Rules.TurnTime
+
+	player.MaxMana = min(player.MaxMana+game.Rules.ManaPerTurn, game.Rules.MaxMana)
+	player.Mana = player.MaxMana
+
+	be.emit(game, Event{Type: EventTurnStarted, PlayerID: player.ID})
+}
@@ -0,0 +1,150 @@
+package battle
+
+import "fmt"
+
+// Outcome is how a finished match went for one player
+type Outcome string
+
+const (
+	OutcomeWin  Outcome = "win"
+	OutcomeLoss Outcome = "loss"
+	OutcomeDraw Outcome = "draw"
+)
+
+// EndReason explains why a match ended
+type EndReason string
+
+const (
+	ReasonHPDepleted EndReason = "hp_depleted"
+	ReasonDeckOut    EndReason = "deck_out"
+	ReasonConcession EndReason = "concession"
+	ReasonTimeout    EndReason = "timeout"
+	ReasonDisconnect EndReason = "disconnect"
+)
+
+// Description returns a short human readable explanation of the reason
+func (r EndReason) Description() string {
+	switch r {
+	case ReasonHPDepleted:
+		return "reduced to 0 HP"
+	case ReasonDeckOut:
+		return "ran out of cards"
+	case ReasonConcession:
+		return "conceded"
+	case ReasonTimeout:
+		return "ran out of time"
+	case ReasonDisconnect:
+		return "disconnected"
+	}
+	return string(r)
+}
+
+// DeckOutRule decides what happens when a player must draw from an empty deck
+type DeckOutRule string
+
+const (
+	// DeckOutFatigue deals FatigueDamage times the number of empty draws so far
+	DeckOutFatigue DeckOutRule = "fatigue"
+	// DeckOutLoss ends the match with the player losing
+	DeckOutLoss DeckOutRule = "loss"
+)
+
+// MatchResult describes how a match ended. Winner and Loser are empty for a draw.
+type MatchResult struct {
+	Winner string    `json:"winner,omitempty"`
+	Loser  string    `json:"loser,omitempty"`
+	Draw   bool      `json:"draw,omitempty"`
+	Reason EndReason `json:"reason"`
+	Turn   int       `json:"turn"`
+}
+
+func (r *MatchResult) copy() *MatchResult {
+	if r == nil {
+		return nil
+	}
+	result := *r
+	return &result
+}
+
+// OutcomeFor returns the outcome of the match for playerID
+func (r *MatchResult) OutcomeFor(playerID string) Outcome {
+	switch {
+	case r.Draw:
+		return OutcomeDraw
+	case r.Winner == playerID:
+		return OutcomeWin
+	}
+	return OutcomeLoss
+}
+
+// Summary describes the result in one line
+func (r *MatchResult) Summary() string {
+	if r.Draw {
+		return fmt.Sprintf("Draw: both players %s", r.Reason.Description())
+	}
+	return fmt.Sprintf("%s wins: %s %s", r.Winner, r.Loser, r.Reason.Description())
+}
+
+// Forfeit ends a match with playerID losing for reason, whether or not it
+// is their turn. The server uses it for timeouts and disconnects.
+func (be *BattleEngine) Forfeit(gameID, playerID string, reason EndReason) (*ActionResult, error) {
+	return be.apply(gameID, playerID, anyVersion, Action{Type: ActionConcede, Reason: reason})
+}
+
+// concede ends the match with player losing
+func (be *BattleEngine) concede(game *GameState, player *Player, reason EndReason) error {
+	if reason == "" {
+		reason = ReasonConcession
+	}
+
+	opponent := be.getOpponent(game, player.ID)
+	be.endGame(game, &MatchResult{Winner: opponent.ID, Loser: player.ID, Reason: reason})
+	return nil
+}
+
+// deckOut applies the deck-out rule to a player who had to draw from an empty deck
+func (be *BattleEngine) deckOut(game *GameState, player *Player) {
+	if game.Rules.DeckOut == DeckOutLoss {
+		opponent := be.getOpponent(game, player.ID)
+		be.endGame(game, &MatchResult{Winner: opponent.ID, Loser: player.ID, Reason: ReasonDeckOut})
+		return
+	}
+
+	player.Fatigue++
+	be.damagePlayer(game, player, player.Fatigue*game.Rules.FatigueDamage)
+}
+
+// checkWinner ends the game once either player's HP reaches 0
+func (be *BattleEngine) checkWinner(game *GameState) {
+	if game.GameOver {
+		return
+	}
+
+	p1Dead := game.Player1.HP <= 0
+	p2Dead := game.Player2.HP <= 0
+
+	switch {
+	case p1Dead && p2Dead:
+		be.endGame(game, &MatchResult{Draw: true, Reason: ReasonHPDepleted})
+	case p1Dead:
+		be.endGame(game, &MatchResult{Winner: game.Player2.ID, Loser: game.Player1.ID, Reason: ReasonHPDepleted})
+	case p2Dead:
+		be.endGame(game, &MatchResult{Winner: game.Player1.ID, Loser: game.Player2.ID, Reason: ReasonHPDepleted})
+	}
+}
+
+// endGame finishes the match with the given result
+func (be *BattleEngine) endGame(game *GameState, result *MatchResult) {
+	if game.GameOver {
+		return
+	}
+
+	result.Turn = game.TurnCount
+	game.Result = result
+	// Whatever was still waiting on the stack never resolves
+	game.Stack = nil
+	game.Priority = ""
+	game.GameOver = true
+	game.Winner = result.Winner
+	game.LastAction = result.Summary()
+}
@@ -0,0 +1,66 @@
+package battle_test
+
+import (
+	"strings"
+	"testing"
+
+	"cardgame/battle"
+)
+
+func TestFatigueGrowsWithEachEmptyDraw(t *testing.T) {
+	be := battle.NewBattleEngine()
+	g := newScriptedMatch(t, be, nil, nil)
+	hp1, hp2 := g.Player1.HP, g.Player2.HP
+
+	s := passTurn(t, be, g.ID)
+	if !strings.Contains(s.LastAction, "empty deck") || s.Phase != battle.PhaseMain {
+		t.Fatalf("got %q in phase %s, want B's draw to fail and the turn to go on", s.LastAction, s.Phase)
+	}
+	if s.Player2.Fatigue != 1 || s.Player2.HP != hp2-100 {
+		t.Fatalf("first empty draw: fatigue %d, HP %d", s.Player2.Fatigue, s.Player2.HP)
+	}
+
+	// Each player's fatigue counts on its own
+	s = passTurn(t, be, g.ID)
+	if s.Player1.Fatigue != 1 || s.Player1.HP != hp1-100 {
+		t.Fatalf("A's first empty draw: fatigue %d, HP %d", s.Player1.Fatigue, s.Player1.HP)
+	}
+	s = passTurn(t, be, g.ID)
+	if s.Player2.Fatigue != 2 || s.Player2.HP != hp2-300 {
+		t.Fatalf("second empty draw: fatigue %d, HP %d, want 2 and %d", s.Player2.Fatigue, s.Player2.HP, hp2-300)
+	}
+}
+
+func TestFatigueCanEndTheMatch(t *testing.T) {
+	be := battle.NewBattleEngine()
+	g := newScriptedMatch(t, be, nil, nil, func(rules *battle.RuleSet) {
+		rules.FatigueDamage = 99999
+	})
+
+	s := passTurn(t, be, g.ID)
+	if !s.GameOver || s.Result.Loser != "B" || s.Result.Reason != battle.ReasonHPDepleted {
+		t.Fatalf("got result %+v, want B to lose on HP", s.Result)
+	}
+}
+
+func TestDeckOutLoss(t *testing.T) {
+	be := battle.NewBattleEngine()
+	g := newScriptedMatch(t, be, nil, nil, func(rules *battle.RuleSet) {
+		rules.DeckOut = battle.DeckOutLoss
+	})
+	hp := g.Player2.HP
+
+	s := passTurn(t, be, g.ID)
+	if !s.GameOver || s.Winner != "A" {
+		t.Fatalf("B drew from an empty deck and the match went on")
+	}
+	if s.Result.Loser != "B" || s.Result.Reason != battle.ReasonDeckOut {
+		t.Fatalf("got result %+v, want B to lose by deck out", s.Result)
+	}
+	if s.Player2.Fatigue != 0 || s.Player2.HP != hp {
+		t.Fatalf("deck out dealt fatigue as well")
+	}
+	if _, err := be.Apply(g.ID, "B", battle.Action{Type: battle.ActionEndTurn}); err == nil {
+		t.Fatalf("played on after the match ended")
+	}
+}
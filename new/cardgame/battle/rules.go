@@ -0,0 +1,154 @@
+package battle
+
+import (
+	"fmt"
+	"time"
+)
+
+// RuleSet holds the parameters a match is played under. A zero limit
+// (hand, field or deck size) means the limit is not enforced.
+type RuleSet struct {
+	Name            string `json:"name"`
+	StartingHP      int    `json:"starting_hp"`
+	StartingMana    int    `json:"starting_mana"`
+	StartingMaxMana int    `json:"starting_max_mana"`
+	ManaPerTurn     int    `json:"mana_per_turn"`
+	MaxMana         int    `json:"max_mana"`
+	OpeningHand     int    `json:"opening_hand"`
+	MaxHandSize     int    `json:"max_hand_size"`
+	MaxFieldSize    int    `json:"max_field_size"`
+	DeckSize        int    `json:"deck_size"`
+
+	// Mulligan opens the match with a mulligan phase in which each player
+	// may shuffle part of their opening hand back and redraw it once
+	Mulligan bool `json:"mulligan"`
+
+	// SummoningSickness stops cards without rush from attacking the turn they are played
+	SummoningSickness bool `json:"summoning_sickness"`
+
+	// DeckOut decides what happens on a draw from an empty deck; with
+	// fatigue the nth empty draw deals n times FatigueDamage
+	DeckOut       DeckOutRule `json:"deck_out"`
+	FatigueDamage int         `json:"fatigue_damage,omitempty"`
+
+	// ArchetypeBonusPercent is the stat bonus per allied card of the same archetype
+	ArchetypeBonusPercent int `json:"archetype_bonus_percent"`
+
+	// TurnTime limits each turn; a turn that runs out ends on its own.
+	// MatchTime is each player's chess clock, running whenever they are
+	// the one to act. Running out of it, or reaching MaxTimeouts turn
+	// timeouts, loses the match. ResponseTime limits each response window;
+	// a player who lets it run out passes. The mulligan phase gets a
+	// turn's worth of time, after which undecided players keep their
+	// hands. Zero disables each control.
+	TurnTime     time.Duration `json:"turn_time,omitempty"`
+	MatchTime    time.Duration `json:"match_time,omitempty"`
+	MaxTimeouts  int           `json:"max_timeouts,omitempty"`
+	ResponseTime time.Duration `json:"response_time,omitempty"`
+}
+
+// Rule preset names
+const (
+	RulesStandard = "standard"
+	RulesClassic  = "classic"
+)
+
+// StandardRules returns the default rules used by both the server and the
+// offline game. Like every preset it has no time controls; hosts that need
+// them, such as the server, add their own.
+func StandardRules() RuleSet {
+	return RuleSet{
+		Name:                  RulesStandard,
+		StartingHP:            8000,
+		StartingMana:          1,
+		StartingMaxMana:       1,
+		ManaPerTurn:           1,
+		MaxMana:               10,
+		OpeningHand:           5,
+		MaxHandSize:           10,
+		MaxFieldSize:          5,
+		DeckSize:              40,
+		Mulligan:              true,
+		SummoningSickness:     true,
+		DeckOut:               DeckOutFatigue,
+		FatigueDamage:         500,
+		ArchetypeBonusPercent: 10,
+	}
+}
+
+// ClassicRules returns the rules the engine originally shipped with:
+// a full mana pool from the first turn, no hand, field or deck limits,
+// no mulligan and no summoning sickness
+func ClassicRules() RuleSet {
+	return RuleSet{
+		Name:                  RulesClassic,
+		StartingHP:            8000,
+		StartingMana:          10,
+		StartingMaxMana:       15,
+		ManaPerTurn:           0,
+		MaxMana:               15,
+		OpeningHand:           5,
+		DeckOut:               DeckOutLoss,
+		ArchetypeBonusPercent: 10,
+	}
+}
+
+// RulesPreset returns the named rule preset
+func RulesPreset(name string) (RuleSet, error) {
+	switch name {
+	case RulesStandard:
+		return StandardRules(), nil
+	case RulesClassic:
+		return ClassicRules(), nil
+	}
+	return RuleSet{}, fmt.Errorf("unknown rule preset %q", name)
+}
+
+// Validate checks that the rules describe a playable match
+func (r RuleSet) Validate() error {
+	if r.StartingHP <= 0 {
+		return fmt.Errorf("starting HP must be positive")
+	}
+	if r.StartingMana < 0 || r.StartingMaxMana < 0 || r.ManaPerTurn < 0 {
+		return fmt.Errorf("mana values cannot be negative")
+	}
+	if r.MaxMana < r.StartingMaxMana {
+		return fmt.Errorf("max mana cannot be below starting max mana")
+	}
+	if r.OpeningHand < 0 {
+		return fmt.Errorf("opening hand cannot be negative")
+	}
+	if r.MaxHandSize > 0 && r.OpeningHand > r.MaxHandSize {
+		return fmt.Errorf("opening hand exceeds max hand size")
+	}
+	if r.DeckSize > 0 && r.OpeningHand > r.DeckSize {
+		return fmt.Errorf("opening hand exceeds deck size")
+	}
+	if r.MaxFieldSize < 0 || r.MaxHandSize < 0 || r.DeckSize < 0 {
+		return fmt.Errorf("limits cannot be negative")
+	}
+	if r.TurnTime < 0 || r.MatchTime < 0 || r.MaxTimeouts < 0 || r.ResponseTime < 0 {
+		return fmt.Errorf("time controls cannot be negative")
+	}
+	switch r.DeckOut {
+	case DeckOutLoss:
+	case DeckOutFatigue:
+		if r.FatigueDamage <= 0 {
+			return fmt.Errorf("fatigue damage must be positive")
+		}
+	default:
+		return fmt.Errorf("unknown deck-out rule %q", r.DeckOut)
+	}
+	return nil
+}
+
+// checkDeck verifies a deck against the rules
+func (r RuleSet) checkDeck(deck []Card) error {
+	if r.DeckSize > 0 && len(deck) != r.DeckSize {
+		return fmt.Errorf("deck must contain %d cards, got %d", r.DeckSize, len(deck))
+	}
+	if len(deck) < r.OpeningHand {
+		return fmt.Errorf("deck is smaller than the opening hand")
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+package battle
+
+// Snapshot returns a deep copy of the game that shares no memory with the
+// engine. Everything the engine hands out, from GetGameState to state
+// change subscriptions, is a snapshot, so readers never see a move half applied
+// and can keep a snapshot as long as they like. Version tells snapshots of
+// the same game apart: it is the number of actions applied so far.
+func (g *GameState) Snapshot() *GameState {
+	snapshot := *g
+	snapshot.Player1 = g.Player1.clone()
+	snapshot.Player2 = g.Player2.clone()
+	snapshot.Result = g.Result.copy()
+	snapshot.Stack = cloneStack(g.Stack)
+
+	// The engine's internals stay with the engine
+	snapshot.events = nil
+	snapshot.rng = nil
+	snapshot.setup = MatchSetup{}
+	snapshot.log = nil
+	snapshot.subscribers = nil
+	return &snapshot
+}
+
+func (p *Player) clone() *Player {
+	player := *p
+	player.Deck = copyCards(p.Deck)
+	player.Hand = copyCards(p.Hand)
+	player.Field = copyCards(p.Field)
+	player.Graveyard = copyCards(p.Graveyard)
+	player.Traps = copyCards(p.Traps)
+	player.ArchetypeBonus = make(map[Archetype]float32, len(p.ArchetypeBonus))
+	for archetype, bonus := range p.ArchetypeBonus {
+		player.ArchetypeBonus[archetype] = bonus
+	}
+	return &player
+}
+
+// clone copies a card along with everything it points to
+func (c Card) clone() Card {
+	c.Keywords = append([]Keyword(nil), c.Keywords...)
+	c.BaseKeywords = append([]Keyword(nil), c.BaseKeywords...)
+	c.Modifiers = append([]Modifier(nil), c.Modifiers...)
+	if c.Attached != nil {
+		c.Attached = copyCards(c.Attached)
+	}
+
+	if c.Effects != nil {
+		effects := make([]CardEffect, len(c.Effects))
+		for i, effect := range c.Effects {
+			effects[i] = effect.clone()
+		}
+		c.Effects = effects
+	}
+	if c.Triggers != nil {
+		triggers := make([]TriggeredAbility, len(c.Triggers))
+		for i, trigger := range c.Triggers {
+			trigger.Effect = trigger.Effect.clone()
+			triggers[i] = trigger
+		}
+		c.Triggers = triggers
+	}
+	return c
+}
+
+func (e CardEffect) clone() CardEffect {
+	if e.Condition != nil {
+		condition := *e.Condition
+		e.Condition = &condition
+	}
+	if e.Modifier != nil {
+		modifier := *e.Modifier
+		e.Modifier = &modifier
+	}
+	return e
+}
+
+func cloneStack(stack []StackItem) []StackItem {
+	if stack == nil {
+		return nil
+	}
+	cloned := make([]StackItem, len(stack))
+	for i, item := range stack {
+		item.Card = item.Card.clone()
+		item.Action.CardIDs = append([]string(nil), item.Action.CardIDs...)
+		cloned[i] = item
+	}
+	return cloned
+}
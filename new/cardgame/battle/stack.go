@@ -0,0 +1,221 @@
+package battle
+
+import "fmt"
+
+// StackItem is a declared move waiting for the players to let it resolve.
+// Card is the card being played, the attacker, or the instant or trap used
+// as a response. Event is how the item looks to traps deciding whether they
+// may respond to it.
+type StackItem struct {
+	PlayerID string `json:"player_id"`
+	Action   Action `json:"action"`
+	Card     Card   `json:"card"`
+	Event    Event  `json:"event"`
+}
+
+// checkPriority reports why playerID may not submit an action of the given
+// type right now, if they may not. During the mulligan phase the only move
+// is the mulligan. While the stack holds anything only the player with
+// priority may act, and only by responding or passing.
+func (be *BattleEngine) checkPriority(game *GameState, playerID string, actionType ActionType) error {
+	if actionType == ActionConcede || actionType == ActionTimeout {
+		return nil
+	}
+
+	if game.Phase == PhaseMulligan {
+		if actionType != ActionMulligan {
+			return fmt.Errorf("waiting for the mulligan to finish")
+		}
+		return nil
+	}
+	if actionType == ActionMulligan {
+		return fmt.Errorf("mulligan is only allowed before the first turn")
+	}
+
+	if len(game.Stack) > 0 {
+		if actionType != ActionRespond && actionType != ActionPass {
+			return fmt.Errorf("waiting for responses to resolve")
+		}
+		if game.Priority != playerID {
+			return fmt.Errorf("you do not have priority")
+		}
+		return nil
+	}
+
+	if actionType == ActionRespond || actionType == ActionPass {
+		return fmt.Errorf("there is nothing to respond to")
+	}
+	if game.CurrentTurn != playerID {
+		return fmt.Errorf("not your turn")
+	}
+	return nil
+}
+
+// declare puts an item on the stack and opens a response window for it
+func (be *BattleEngine) declare(game *GameState, item StackItem) {
+	game.Stack = append(game.Stack, item)
+	be.settleStack(game)
+}
+
+// settleStack hands priority to the opponent of whoever controls the top of
+// the stack. The window opens whether or not they hold a response: opening
+// it only when they do would tell the other player what is in their hand
+// and trap zone. Their client may pass for them when they have nothing.
+func (be *BattleEngine) settleStack(game *GameState) {
+	if len(game.Stack) == 0 || game.GameOver {
+		game.Priority = ""
+		return
+	}
+	top := game.Stack[len(game.Stack)-1]
+	responder := be.getOpponent(game, top.PlayerID)
+	game.Priority = responder.ID
+	game.LastAction = fmt.Sprintf("%s may respond to %s", responder.ID, top.Card.Name)
+}
+
+// checkRespond reports why player cannot respond to the top of the stack with
+// cardID, if they cannot. Instants are played from hand; traps are flipped
+// from the trap zone when one of their triggers matches the top item.
+func (be *BattleEngine) checkRespond(game *GameState, player *Player, cardID string) error {
+	if len(game.Stack) == 0 {
+		return fmt.Errorf("there is nothing to respond to")
+	}
+	top := game.Stack[len(game.Stack)-1]
+
+	if index := findCard(player.Hand, cardID); index != -1 {
+		card := player.Hand[index]
+		if card.Type != CardInstant {
+			return fmt.Errorf("only instants can be played in response")
+		}
+		if card.Cost > player.Mana {
+			return fmt.Errorf("insufficient mana")
+		}
+		return nil
+	}
+
+	if index := findCard(player.Traps, cardID); index != -1 {
+		for _, ability := range player.Traps[index].Triggers {
+			if ability.matches(top.Event, player.ID) {
+				return nil
+			}
+		}
+		return fmt.Errorf("trap does not react to %s", top.Card.Name)
+	}
+
+	return fmt.Errorf("card not in hand or trap zone")
+}
+
+// respond puts an instant or a flipped trap on top of the stack
+func (be *BattleEngine) respond(game *GameState, player *Player, cardID string) error {
+	if err := be.checkRespond(game, player, cardID); err != nil {
+		return err
+	}
+
+	item := StackItem{PlayerID: player.ID, Action: Action{Type: ActionRespond, CardID: cardID}}
+
+	if index := findCard(player.Hand, cardID); index != -1 {
+		item.Card = player.Hand[index]
+		player.Hand = append(player.Hand[:index], player.Hand[index+1:]...)
+		player.Mana -= item.Card.Cost
+		item.Event = Event{Type: EventCardPlayed, PlayerID: player.ID, CardID: item.Card.ID, InstanceID: item.Card.InstanceID, CardName: item.Card.Name}
+	} else {
+		index := findCard(player.Traps, cardID)
+		item.Card = player.Traps[index]
+		player.Traps = append(player.Traps[:index], player.Traps[index+1:]...)
+		item.Event = Event{Type: EventTrapTriggered, PlayerID: player.ID, CardID: item.Card.ID, InstanceID: item.Card.InstanceID, CardName: item.Card.Name}
+		be.emit(game, item.Event)
+	}
+
+	be.declare(game, item)
+	return nil
+}
+
+// pass gives up priority, which resolves the top of the stack
+func (be *BattleEngine) pass(game *GameState) error {
+	be.resolveTop(game)
+	be.settleStack(game)
+	return nil
+}
+
+// resolveTop takes the top item off the stack and carries it out
+func (be *BattleEngine) resolveTop(game *GameState) {
+	item := game.Stack[len(game.Stack)-1]
+	game.Stack = game.Stack[:len(game.Stack)-1]
+	player := be.getPlayer(game, item.PlayerID)
+
+	switch item.Action.Type {
+	case ActionPlayCard:
+		be.resolvePlay(game, player, item)
+	case ActionAttack:
+		be.resolveAttack(game, player, item)
+	case ActionRespond:
+		be.resolveResponse(game, player, item)
+	}
+}
+
+// resolvePlay puts a played card into effect according to its type
+func (be *BattleEngine) resolvePlay(game *GameState, player *Player, item StackItem) {
+	switch item.Card.Type {
+	case CardSpell:
+		be.castSpell(game, player, item.Card)
+	case CardEquipment:
+		be.equip(game, player, item.Card, item.Action.TargetID)
+	default:
+		be.summon(game, player, item.Card)
+	}
+}
+
+// resolveResponse resolves an instant or trap against the item it responded
+// to, which is the new top of the stack
+func (be *BattleEngine) resolveResponse(game *GameState, player *Player, item StackItem) {
+	var responded *Event
+	if len(game.Stack) > 0 {
+		event := game.Stack[len(game.Stack)-1].Event
+		responded = &event
+	}
+
+	card := item.Card
+	player.Graveyard = append(player.Graveyard, card)
+
+	effects := card.Effects
+	if card.Type == CardTrap {
+		effects = nil
+		for _, ability := range card.Triggers {
+			if responded != nil && ability.matches(*responded, player.ID) {
+				effects = append(effects, ability.Effect)
+			}
+		}
+		game.LastAction = fmt.Sprintf("%s sprang %s", player.ID, card.Name)
+	} else {
+		game.LastAction = fmt.Sprintf("%s cast %s", player.ID, card.Name)
+	}
+
+	for _, effect := range effects {
+		if effect.Kind == EffectCounter {
+			be.counter(game)
+			continue
+		}
+		be.resolveEffect(game, player, effect, responded)
+	}
+
+	if card.Type == CardInstant {
+		be.emit(game, Event{Type: EventCardPlayed, PlayerID: player.ID, CardID: card.ID, InstanceID: card.InstanceID, CardName: card.Name})
+	}
+}
+
+// counter removes the top of the stack without resolving it. A countered
+// card goes to its owner's graveyard; a countered attack never happens,
+// though the attacker stays exhausted.
+func (be *BattleEngine) counter(game *GameState) {
+	if len(game.Stack) == 0 {
+		return
+	}
+
+	item := game.Stack[len(game.Stack)-1]
+	game.Stack = game.Stack[:len(game.Stack)-1]
+
+	if item.Action.Type != ActionAttack {
+		owner := be.getPlayer(game, item.PlayerID)
+		owner.Graveyard = append(owner.Graveyard, item.Card)
+	}
+	game.LastAction = fmt.Sprintf("%s was countered", item.Card.Name)
+}
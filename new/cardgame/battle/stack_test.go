@@ -0,0 +1,166 @@
+package battle_test
+
+import (
+	"strings"
+	"testing"
+
+	"cardgame/battle"
+)
+
+var (
+	// spark and bolt hurt the opponent of whoever plays them
+	spark = instant("Spark", battle.CardEffect{Kind: battle.EffectDamage, Amount: 100, Target: battle.TargetOpponent})
+	bolt  = instant("Bolt", battle.CardEffect{Kind: battle.EffectDamage, Amount: 500, Target: battle.TargetOpponent})
+	// negate counters whatever it responds to
+	negate = instant("Negate", battle.CardEffect{Kind: battle.EffectCounter})
+	// smite destroys the attacker it responds to
+	smite = instant("Smite", battle.CardEffect{Kind: battle.EffectDamage, Amount: 9999, Target: battle.TargetTriggeringCard})
+	// quake destroys every card on the opponent's field
+	quake = instant("Quake", battle.CardEffect{Kind: battle.EffectDamage, Amount: 9999, Target: battle.TargetEnemyField})
+)
+
+func TestStackResolvesLastInFirstOut(t *testing.T) {
+	be := battle.NewBattleEngine()
+	g := newScriptedMatch(t, be,
+		[]battle.Card{creature("Wolf", 500, 500), spark},
+		[]battle.Card{bolt})
+
+	s := mustApply(t, be, g.ID, "A", battle.Action{Type: battle.ActionPlayCard, CardID: instanceID(t, g.Player1.Hand, "Wolf")})
+	s = mustApply(t, be, g.ID, "B", battle.Action{Type: battle.ActionRespond, CardID: instanceID(t, g.Player2.Hand, "Bolt")})
+	if s.Priority != "A" {
+		t.Fatalf("priority went to %q after B responded, want A", s.Priority)
+	}
+	s = mustApply(t, be, g.ID, "A", battle.Action{Type: battle.ActionRespond, CardID: instanceID(t, g.Player1.Hand, "Spark")})
+	if len(s.Stack) != 3 || s.Priority != "B" {
+		t.Fatalf("got a stack of %d with priority %q, want 3 with B", len(s.Stack), s.Priority)
+	}
+
+	// Each pass resolves the top item and hands priority to its controller's opponent
+	hp1, hp2 := s.Player1.HP, s.Player2.HP
+	s = mustApply(t, be, g.ID, "B", battle.Action{Type: battle.ActionPass})
+	if s.Player2.HP != hp2-100 || s.Player1.HP != hp1 || s.Priority != "A" {
+		t.Fatalf("Spark did not resolve first")
+	}
+	s = mustApply(t, be, g.ID, "A", battle.Action{Type: battle.ActionPass})
+	if s.Player1.HP != hp1-500 || len(s.Player1.Field) != 0 || s.Priority != "B" {
+		t.Fatalf("Bolt did not resolve second")
+	}
+	s = mustApply(t, be, g.ID, "B", battle.Action{Type: battle.ActionPass})
+	if len(s.Stack) != 0 || s.Priority != "" || len(s.Player1.Field) != 1 {
+		t.Fatalf("Wolf did not resolve last")
+	}
+}
+
+func TestResponseWindowOpensWithoutResponses(t *testing.T) {
+	be := battle.NewBattleEngine()
+	withInstant := newScriptedMatch(t, be, []battle.Card{creature("Wolf", 500, 500)}, []battle.Card{spark})
+	without := newScriptedMatch(t, be, []battle.Card{creature("Wolf", 500, 500)}, nil)
+
+	// Whether B can answer must not show in what A sees
+	var views []*battle.GameView
+	for _, g := range []*battle.GameState{withInstant, without} {
+		s := mustApply(t, be, g.ID, "A", battle.Action{Type: battle.ActionPlayCard, CardID: instanceID(t, g.Player1.Hand, "Wolf")})
+		if s.Priority != "B" || len(s.Stack) != 1 {
+			t.Fatalf("no response window opened for B")
+		}
+		if legal, _ := be.LegalActions(g.ID, "A"); len(legal) != 0 {
+			t.Fatalf("A may act while B holds priority: %v", legal)
+		}
+		if _, err := be.Apply(g.ID, "A", battle.Action{Type: battle.ActionEndTurn}); err == nil {
+			t.Fatalf("A ended the turn while B held priority")
+		}
+		views = append(views, s.ViewFor("A"))
+	}
+	if views[0].Priority != views[1].Priority || views[0].LastAction != views[1].LastAction {
+		t.Fatalf("A's view tells whether B holds a response")
+	}
+
+	legal, _ := be.LegalActions(without.ID, "B")
+	if len(legal) != 1 || legal[0].Type != battle.ActionPass {
+		t.Fatalf("B without responses may %v, want only a pass", legal)
+	}
+	s := mustApply(t, be, without.ID, "B", battle.Action{Type: battle.ActionPass})
+	if len(s.Player1.Field) != 1 {
+		t.Fatalf("Wolf did not resolve after B passed")
+	}
+}
+
+func TestCounterRemovesTopItem(t *testing.T) {
+	be := battle.NewBattleEngine()
+	g := newScriptedMatch(t, be, []battle.Card{creature("Wolf", 500, 500)}, []battle.Card{negate})
+
+	mustApply(t, be, g.ID, "A", battle.Action{Type: battle.ActionPlayCard, CardID: instanceID(t, g.Player1.Hand, "Wolf")})
+	mustApply(t, be, g.ID, "B", battle.Action{Type: battle.ActionRespond, CardID: instanceID(t, g.Player2.Hand, "Negate")})
+	s := mustApply(t, be, g.ID, "A", battle.Action{Type: battle.ActionPass})
+
+	if len(s.Stack) != 0 || s.Priority != "" {
+		t.Fatalf("stack not empty after the counter: %d items, priority %q", len(s.Stack), s.Priority)
+	}
+	if len(s.Player1.Field) != 0 {
+		t.Fatalf("countered Wolf reached the field")
+	}
+	if len(s.Player1.Graveyard) != 1 || s.Player1.Graveyard[0].Name != "Wolf" {
+		t.Fatalf("countered Wolf is not in the graveyard")
+	}
+	if len(s.Player2.Graveyard) != 1 || s.Player2.Graveyard[0].Name != "Negate" {
+		t.Fatalf("Negate is not in the graveyard")
+	}
+}
+
+func TestAttackFizzlesWithoutAttacker(t *testing.T) {
+	be := battle.NewBattleEngine()
+	g := newScriptedMatch(t, be, []battle.Card{creature("Wolf", 500, 500)}, []battle.Card{smite})
+	s := play(t, be, g.ID, "A", "Wolf")
+	mustApply(t, be, g.ID, "A", battle.Action{Type: battle.ActionChangePhase, Phase: battle.PhaseBattle})
+	hp1, hp2 := s.Player1.HP, s.Player2.HP
+
+	wolf := instanceID(t, s.Player1.Field, "Wolf")
+	mustApply(t, be, g.ID, "A", battle.Action{Type: battle.ActionAttack, AttackerID: wolf})
+	mustApply(t, be, g.ID, "B", battle.Action{Type: battle.ActionRespond, CardID: instanceID(t, g.Player2.Hand, "Smite")})
+	s = mustApply(t, be, g.ID, "A", battle.Action{Type: battle.ActionPass})
+	if len(s.Player1.Field) != 0 || s.Priority != "B" {
+		t.Fatalf("Smite did not destroy Wolf before the attack resolved")
+	}
+
+	s = mustApply(t, be, g.ID, "B", battle.Action{Type: battle.ActionPass})
+	if !strings.Contains(s.LastAction, "fizzled") {
+		t.Fatalf("got %q, want the attack to fizzle", s.LastAction)
+	}
+	if s.Player2.HP != hp2 || s.Player1.HP != hp1 {
+		t.Fatalf("fizzled attack changed HP to %d and %d", s.Player1.HP, s.Player2.HP)
+	}
+}
+
+func TestAttackFizzlesWithoutTarget(t *testing.T) {
+	be := battle.NewBattleEngine()
+	g := newScriptedMatch(t, be,
+		[]battle.Card{creature("Wolf", 500, 500, battle.KeywordLifesteal), quake},
+		[]battle.Card{creature("Bear", 100, 1000), bolt})
+	passTurn(t, be, g.ID)
+	play(t, be, g.ID, "B", "Bear")
+	passTurn(t, be, g.ID)
+	s := play(t, be, g.ID, "A", "Wolf")
+	mustApply(t, be, g.ID, "A", battle.Action{Type: battle.ActionChangePhase, Phase: battle.PhaseBattle})
+
+	// A attacks Bear, B answers with Bolt and A clears B's field in response
+	mustApply(t, be, g.ID, "A", battle.Action{Type: battle.ActionAttack, AttackerID: instanceID(t, s.Player1.Field, "Wolf"), TargetID: instanceID(t, s.Player2.Field, "Bear")})
+	mustApply(t, be, g.ID, "B", battle.Action{Type: battle.ActionRespond, CardID: instanceID(t, g.Player2.Hand, "Bolt")})
+	mustApply(t, be, g.ID, "A", battle.Action{Type: battle.ActionRespond, CardID: instanceID(t, g.Player1.Hand, "Quake")})
+	s = mustApply(t, be, g.ID, "B", battle.Action{Type: battle.ActionPass})
+	if len(s.Player2.Field) != 0 {
+		t.Fatalf("Quake did not destroy Bear")
+	}
+	s = mustApply(t, be, g.ID, "A", battle.Action{Type: battle.ActionPass})
+	hp1, hp2 := s.Player1.HP, s.Player2.HP
+
+	s = mustApply(t, be, g.ID, "B", battle.Action{Type: battle.ActionPass})
+	if !strings.Contains(s.LastAction, "fizzled") {
+		t.Fatalf("got %q, want the attack to fizzle", s.LastAction)
+	}
+	if s.Player2.HP != hp2 || s.Player1.HP != hp1 {
+		t.Fatalf("fizzled attack changed HP to %d and %d", s.Player1.HP, s.Player2.HP)
+	}
+	if len(s.Player1.Field) != 1 || !s.Player1.Field[0].HasAttacked {
+		t.Fatalf("Wolf should stay on the field, exhausted")
+	}
+}
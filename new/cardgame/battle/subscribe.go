@@ -0,0 +1,145 @@
+package battle
+
+import (
+	"fmt"
+	"sync"
+)
+
+// DefaultSubscriptionBuffer is how many state changes a subscriber may fall
+// behind by when Subscribe is not given a buffer size
+const DefaultSubscriptionBuffer = 64
+
+// StateChange is a snapshot of a game delivered to subscribers. Seq is the
+// snapshot's Version; it only ever grows within a subscription. Legal holds
+// the moves each player may make in that snapshot, keyed by player ID.
+type StateChange struct {
+	Seq   int                 `json:"seq"`
+	State *GameState          `json:"state"`
+	Legal map[string][]Action `json:"legal,omitempty"`
+}
+
+// stateChange takes a snapshot of the game and the moves open in it
+func (be *BattleEngine) stateChange(game *GameState) StateChange {
+	return StateChange{
+		Seq:   game.Version,
+		State: game.Snapshot(),
+		Legal: map[string][]Action{
+			game.Player1.ID: be.legalActions(game, game.Player1.ID),
+			game.Player2.ID: be.legalActions(game, game.Player2.ID),
+		},
+	}
+}
+
+// Subscription delivers every state change of one game, in order, to a
+// single listener. Any number of subscriptions may watch the same game.
+type Subscription struct {
+	match   *match
+	updates chan StateChange
+
+	// closed and err are written on the match goroutine and read by the
+	// subscriber
+	mu     sync.Mutex
+	closed bool
+	err    error
+}
+
+// Subscribe starts listening to a game. The first state change delivered
+// is the game as it is now, so nothing is missed between reading the state
+// and subscribing. The engine never waits on a subscriber: one that falls
+// more than buffer changes behind is dropped, with Err saying why.
+func (be *BattleEngine) Subscribe(gameID string, buffer int) (*Subscription, error) {
+	if buffer <= 0 {
+		buffer = DefaultSubscriptionBuffer
+	}
+
+	var sub *Subscription
+	err := be.run(gameID, func(m *match) error {
+		sub = &Subscription{
+			match:   m,
+			updates: make(chan StateChange, buffer),
+		}
+		sub.updates <- be.stateChange(m.game)
+		m.game.subscribers = append(m.game.subscribers, sub)
+		return nil
+	})
+	return sub, err
+}
+
+// Updates returns the channel state changes arrive on. It is closed once
+// the subscription ends.
+func (s *Subscription) Updates() <-chan StateChange {
+	return s.updates
+}
+
+// Unsubscribe stops the subscription and closes its channel. It is safe to
+// call more than once.
+func (s *Subscription) Unsubscribe() {
+	err := s.match.do(func() {
+		game := s.match.game
+		for i, sub := range game.subscribers {
+			if sub == s {
+				game.subscribers = append(game.subscribers[:i:i], game.subscribers[i+1:]...)
+				break
+			}
+		}
+		s.close(nil)
+	})
+
+	// A stopped match closes its subscriptions as it exits
+	if err != nil {
+		<-s.match.done
+	}
+}
+
+// Err reports why the engine ended the subscription. It is nil while the
+// subscription is open and after Unsubscribe.
+func (s *Subscription) Err() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.err
+}
+
+// close ends the subscription. Only the match goroutine closes
+// subscriptions, so nothing is ever sent on a closed channel.
+func (s *Subscription) close(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return
+	}
+	s.closed = true
+	s.err = err
+	close(s.updates)
+}
+
+// notifyStateChange hands a snapshot of the game to every subscriber.
+// It runs on the match goroutine, so changes go out in the order they happen.
+func (be *BattleEngine) notifyStateChange(game *GameState) {
+	subs := game.subscribers
+	if len(subs) == 0 {
+		return
+	}
+
+	// Snapshots are never modified, so every subscriber can share one
+	change := be.stateChange(game)
+	kept := subs[:0]
+	for _, sub := range subs {
+		select {
+		case sub.updates <- change:
+			kept = append(kept, sub)
+		default:
+			sub.close(fmt.Errorf("subscriber fell more than %d changes behind", cap(sub.updates)))
+		}
+	}
+	clear(subs[len(kept):])
+	game.subscribers = kept
+}
+
+// closeSubscriptions ends every subscription to a game that is going away
+func closeSubscriptions(game *GameState) {
+	for _, sub := range game.subscribers {
+		sub.close(fmt.Errorf("match closed"))
+	}
+	game.subscribers = nil
+}
@@ -0,0 +1,167 @@
+package battle
+
+import "time"
+
+// PlayerView is a player as seen by a particular viewer. Hidden zones are
+// reduced to counts: the deck order is never exposed and the hand and
+// face-down traps are only filled in for the player they belong to.
+type PlayerView struct {
+	ID             string                `json:"id"`
+	Name           string                `json:"name"`
+	HP             int                   `json:"hp"`
+	Mana           int                   `json:"mana"`
+	MaxMana        int                   `json:"max_mana"`
+	Hand           []Card                `json:"hand,omitempty"`
+	HandCount      int                   `json:"hand_count"`
+	DeckCount      int                   `json:"deck_count"`
+	Field          []Card                `json:"field"`
+	Graveyard      []Card                `json:"graveyard"`
+	Traps          []Card                `json:"traps,omitempty"`
+	TrapCount      int                   `json:"trap_count"`
+	ArchetypeBonus map[Archetype]float32 `json:"archetype_bonus"`
+	Fatigue        int                   `json:"fatigue,omitempty"`
+	MulliganDone   bool                  `json:"mulligan_done,omitempty"`
+	TimeBank       time.Duration         `json:"time_bank,omitempty"`
+	Timeouts       int                   `json:"timeouts,omitempty"`
+}
+
+// GameView is the redacted game state sent to a player or spectator. Clock
+// times are as of the last accepted action; clients count them down from
+// there while ClockHolder's time runs.
+type GameView struct {
+	ID          string       `json:"id"`
+	ViewerID    string       `json:"viewer_id,omitempty"`
+	Player1     *PlayerView  `json:"player1"`
+	Player2     *PlayerView  `json:"player2"`
+	CurrentTurn string       `json:"current_turn"`
+	TurnCount   int          `json:"turn_count"`
+	Phase       GamePhase    `json:"phase"`
+	Winner      string       `json:"winner"`
+	GameOver    bool         `json:"game_over"`
+	LastAction  string       `json:"last_action"`
+	Version     int          `json:"version"`
+	Rules       RuleSet      `json:"rules"`
+	Result      *MatchResult `json:"result,omitempty"`
+	Stack       []StackItem  `json:"stack,omitempty"`
+	Priority    string       `json:"priority,omitempty"`
+
+	TurnTimeLeft time.Duration `json:"turn_time_left,omitempty"`
+	ClockHolder  string        `json:"clock_holder,omitempty"`
+}
+
+// ViewFor returns the state as seen by playerID. Any ID that does not belong
+// to either player gets the spectator view.
+func (g *GameState) ViewFor(playerID string) *GameView {
+	return &GameView{
+		ID:          g.ID,
+		ViewerID:    playerID,
+		Player1:     g.Player1.viewFor(playerID),
+		Player2:     g.Player2.viewFor(playerID),
+		CurrentTurn: g.CurrentTurn,
+		TurnCount:   g.TurnCount,
+		Phase:       g.Phase,
+		Winner:      g.Winner,
+		GameOver:    g.GameOver,
+		LastAction:  g.LastAction,
+		Version:     g.Version,
+		Rules:       g.Rules,
+		Result:      g.Result.copy(),
+		Stack:       cloneStack(g.Stack),
+		Priority:    g.Priority,
+
+		TurnTimeLeft: g.TurnTimeLeft,
+		ClockHolder:  g.ClockHolder,
+	}
+}
+
+// SpectatorView returns the state with both hands hidden
+func (g *GameState) SpectatorView() *GameView {
+	return g.ViewFor("")
+}
+
+// GetGameView returns the game state as seen by playerID
+func (be *BattleEngine) GetGameView(gameID, playerID string) (*GameView, error) {
+	var view *GameView
+	err := be.run(gameID, func(m *match) error {
+		view = m.game.ViewFor(playerID)
+		return nil
+	})
+	return view, err
+}
+
+// GetGameViewWithActions returns the game as seen by playerID together with
+// the moves open to them, both as of the same version
+func (be *BattleEngine) GetGameViewWithActions(gameID, playerID string) (*GameView, []Action, error) {
+	var view *GameView
+	var legal []Action
+	err := be.run(gameID, func(m *match) error {
+		view = m.game.ViewFor(playerID)
+		legal = be.legalActions(m.game, playerID)
+		return nil
+	})
+	return view, legal, err
+}
+
+// Me returns the viewer's own player, or nil for spectators
+func (v *GameView) Me() *PlayerView {
+	switch v.ViewerID {
+	case "":
+		return nil
+	case v.Player1.ID:
+		return v.Player1
+	case v.Player2.ID:
+		return v.Player2
+	}
+	return nil
+}
+
+// Opponent returns the player facing the viewer, or nil for spectators
+func (v *GameView) Opponent() *PlayerView {
+	switch v.Me() {
+	case nil:
+		return nil
+	case v.Player1:
+		return v.Player2
+	}
+	return v.Player1
+}
+
+func (p *Player) viewFor(viewerID string) *PlayerView {
+	view := &PlayerView{
+		ID:             p.ID,
+		Name:           p.Name,
+		HP:             p.HP,
+		Mana:           p.Mana,
+		MaxMana:        p.MaxMana,
+		HandCount:      len(p.Hand),
+		DeckCount:      len(p.Deck),
+		TrapCount:      len(p.Traps),
+		Field:          copyCards(p.Field),
+		Graveyard:      copyCards(p.Graveyard),
+		ArchetypeBonus: make(map[Archetype]float32, len(p.ArchetypeBonus)),
+		Fatigue:        p.Fatigue,
+		MulliganDone:   p.MulliganDone,
+		TimeBank:       p.TimeBank,
+		Timeouts:       p.Timeouts,
+	}
+
+	if viewerID != "" && viewerID == p.ID {
+		view.Hand = copyCards(p.Hand)
+		view.Traps = copyCards(p.Traps)
+	}
+
+	for archetype, bonus := range p.ArchetypeBonus {
+		view.ArchetypeBonus[archetype] = bonus
+	}
+
+	return view
+}
+
+// copyCards deep copies a list of cards
+func copyCards(cards []Card) []Card {
+	copied := make([]Card, len(cards))
+	for i, card := range cards {
+		copied[i] = card.clone()
+	}
+	return copied
+}
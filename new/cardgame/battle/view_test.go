@@ -0,0 +1,52 @@
+package battle_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"cardgame/battle"
+)
+
+func TestViewHidesOpponentsHandAndTraps(t *testing.T) {
+	be := battle.NewBattleEngine()
+	g := newScriptedMatch(t, be, []battle.Card{pitfall, spark, creature("Wolf", 500, 500)}, []battle.Card{bolt})
+	s := mustApply(t, be, g.ID, "A", battle.Action{Type: battle.ActionPlayCard, CardID: instanceID(t, g.Player1.Hand, "Pitfall")})
+	if len(s.Player1.Traps) != 1 {
+		t.Fatalf("Pitfall was not set")
+	}
+
+	for _, viewer := range []string{"B", ""} {
+		view := s.ViewFor(viewer)
+		hidden := view.Player1
+		if len(hidden.Hand) != 0 || len(hidden.Traps) != 0 {
+			t.Fatalf("viewer %q sees A's hand or traps", viewer)
+		}
+		if hidden.HandCount != 2 || hidden.TrapCount != 1 || hidden.DeckCount != len(s.Player1.Deck) {
+			t.Fatalf("viewer %q got counts %d/%d/%d, want 2/1/%d", viewer, hidden.HandCount, hidden.TrapCount, hidden.DeckCount, len(s.Player1.Deck))
+		}
+
+		// Nothing about hidden cards may reach the wire
+		raw, err := json.Marshal(view)
+		if err != nil {
+			t.Fatalf("encoding view: %v", err)
+		}
+		for _, card := range append(s.Player1.Hand, s.Player1.Traps...) {
+			if strings.Contains(string(raw), card.InstanceID) || strings.Contains(string(raw), card.Name) {
+				t.Fatalf("viewer %q can read %s in the encoded view", viewer, card.Name)
+			}
+		}
+	}
+	if view := s.ViewFor(""); len(view.Player2.Hand) != 0 || view.Player2.HandCount != len(s.Player2.Hand) {
+		t.Fatalf("spectators see B's hand")
+	}
+
+	// Players see their own hidden cards
+	own := s.ViewFor("A").Player1
+	if len(own.Hand) != 2 || len(own.Traps) != 1 || own.Traps[0].Name != "Pitfall" {
+		t.Fatalf("A does not see their own hand and traps")
+	}
+	if mine := s.ViewFor("B").Me(); len(mine.Hand) != len(s.Player2.Hand) || mine.Hand[0].InstanceID != s.Player2.Hand[0].InstanceID {
+		t.Fatalf("B does not see their own hand")
+	}
+}
@@ -5,9 +5,9 @@ import (
 	"cardgame/battle"
 	"cardgame/game"
 	"cardgame/shared"
+	"encoding/json"
 	"fmt"
 	"os"
-	"strconv"
 	"strings"
 	"sync"
 
@@ -18,11 +18,14 @@ import (
 type GameClient struct {
 	serverAddr string
 	conn       *websocket.Conn
+	codec      *battle.Codec
 	playerID   string
 	playerName string
 	playerNum  int
 	gameID     string
-	gameState  *battle.GameState
+	gameState  *battle.GameView
+	legal      []battle.Action
+	desyncSeq  int
 	display    *game.Display
 	input      *bufio.Reader
 	mu         sync.RWMutex
@@ -45,9 +48,10 @@ func (gc *GameClient) SetPlayerName(name string) {
 	gc.playerName = name
 }
 
-// Connect connects to the game server
+// Connect connects to the game server. Game updates and actions travel in
+// the battle codec's binary encoding.
 func (gc *GameClient) Connect() error {
-	url := "ws://" + gc.serverAddr + "/ws"
+	url := "ws://" + gc.serverAddr + "/ws?encoding=binary"
 	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
 	if err != nil {
 		return fmt.Errorf("failed to connect: %v", err)
@@ -143,8 +147,11 @@ func (gc *GameClient) joinQueue() {
 	fmt.Println(game.ColorCyan + "=====================================" + game.ColorReset)
 	fmt.Println(game.ColorCyan + "        DECK SELECTION              " + game.ColorReset)
 	fmt.Println(game.ColorCyan + "=====================================" + game.ColorReset)
-	fmt.Println("\n1. Egyptian Gods Deck (Attack focused)")
-	fmt.Println("2. Greek Gods Deck (Defense focused)")
+	fmt.Println()
+	for i, archetype := range []battle.Archetype{battle.ArchetypeEgyptian, battle.ArchetypeGreek} {
+		def, _ := battle.LookupArchetype(archetype)
+		fmt.Printf("%d. %s Deck (%s)\n", i+1, def.Name, def.Description)
+	}
 
 	fmt.Print("\nChoose your deck (1 or 2): ")
 	choice, _ := gc.input.ReadString('\n')
@@ -185,9 +192,20 @@ func (gc *GameClient) runGame() {
 	// Display game state
 	gc.displayGameState()
 
-	// Check if it's our turn
-	isOurTurn := (gc.playerNum == 1 && state.CurrentTurn == state.Player1.ID) ||
-		(gc.playerNum == 2 && state.CurrentTurn == state.Player2.ID)
+	// Check if it's our turn, or our chance to respond to the stack
+	ourID := state.Player1.ID
+	if gc.playerNum == 2 {
+		ourID = state.Player2.ID
+	}
+	isOurTurn := state.CurrentTurn == ourID
+	if state.Priority != "" {
+		isOurTurn = state.Priority == ourID
+	}
+
+	// Both players decide on their opening hands before the first turn
+	if state.Phase == battle.PhaseMulligan {
+		isOurTurn = !gc.getOurPlayer().MulliganDone
+	}
 
 	if !isOurTurn {
 		fmt.Println("\n" + game.ColorYellow + "Waiting for opponent's move..." + game.ColorReset)
@@ -196,16 +214,33 @@ func (gc *GameClient) runGame() {
 	}
 
 	// Auto-draw at start of turn
-	if state.Phase == battle.PhaseDrawn {
+	if state.Phase == battle.PhaseDrawn && state.Priority == "" {
 		fmt.Println("\n" + game.ColorGreen + "Drawing card..." + game.ColorReset)
-		gc.sendMessage(shared.Message{
-			Type: shared.MsgDrawCard,
-		})
+		gc.sendAction(battle.Action{Type: battle.ActionDraw})
+		return
+	}
+
+	// Response windows open even when we have nothing to respond with
+	gc.mu.RLock()
+	onlyPass := state.Priority != "" && len(gc.legal) == 1 && gc.legal[0].Type == battle.ActionPass
+	gc.mu.RUnlock()
+	if onlyPass {
+		gc.sendAction(battle.Action{Type: battle.ActionPass})
 		return
 	}
 
 	// Show commands and get input
-	gc.display.ShowCommands(state.Phase, true)
+	if state.Phase == battle.PhaseMulligan {
+		gc.display.ShowMulliganCommands()
+	} else if state.Priority != "" {
+		gc.display.ShowResponseCommands()
+	} else {
+		gc.display.ShowCommands(state.Phase, true)
+	}
+
+	gc.mu.RLock()
+	gc.display.ShowLegalActions(gc.legal)
+	gc.mu.RUnlock()
 
 	fmt.Printf("\n%s > ", gc.playerName)
 	input, _ := gc.input.ReadString('\n')
@@ -228,64 +263,83 @@ func (gc *GameClient) processCommand(command string, args []string) {
 			fmt.Println(game.ColorRed + "Usage: play [card number]" + game.ColorReset)
 			return
 		}
-		cardIndex, err := strconv.Atoi(args[0])
+		cardID, err := game.ResolveCard(gc.getOurPlayer().Hand, args[0])
 		if err != nil {
-			fmt.Println(game.ColorRed + "Invalid card number" + game.ColorReset)
+			fmt.Println(game.ColorRed + "Invalid card: " + err.Error() + game.ColorReset)
 			return
 		}
-		gc.sendMessage(shared.Message{
-			Type: shared.MsgPlayCard,
-			Data: map[string]interface{}{
-				"cardIndex": cardIndex,
-			},
-		})
+		// Equipment names the field card it attaches to
+		targetID := ""
+		if len(args) > 1 {
+			if targetID, err = game.ResolveCard(gc.getOurPlayer().Field, args[1]); err != nil {
+				fmt.Println(game.ColorRed + "Invalid target: " + err.Error() + game.ColorReset)
+				return
+			}
+		}
+		gc.sendAction(battle.Action{Type: battle.ActionPlayCard, CardID: cardID, TargetID: targetID})
 
 	case "attack":
 		if len(args) < 2 {
 			fmt.Println(game.ColorRed + "Usage: attack [attacker] [target]" + game.ColorReset)
 			return
 		}
-		attackerIndex, err1 := strconv.Atoi(args[0])
-		targetIndex, err2 := strconv.Atoi(args[1])
-		if err1 != nil || err2 != nil {
-			fmt.Println(game.ColorRed + "Invalid indices" + game.ColorReset)
+		attackerID, err := game.ResolveCard(gc.getOurPlayer().Field, args[0])
+		if err != nil {
+			fmt.Println(game.ColorRed + "Invalid attacker: " + err.Error() + game.ColorReset)
 			return
 		}
-		gc.sendMessage(shared.Message{
-			Type: shared.MsgAttack,
-			Data: map[string]interface{}{
-				"attackerIndex": attackerIndex,
-				"targetIndex":   targetIndex,
-			},
-		})
+		targetID := ""
+		if args[1] != "-1" && strings.ToLower(args[1]) != "direct" {
+			targetID, err = game.ResolveCard(gc.getOpponentPlayer().Field, args[1])
+			if err != nil {
+				fmt.Println(game.ColorRed + "Invalid target: " + err.Error() + game.ColorReset)
+				return
+			}
+		}
+		gc.sendAction(battle.Action{Type: battle.ActionAttack, AttackerID: attackerID, TargetID: targetID})
 
 	case "battle":
-		gc.sendMessage(shared.Message{
-			Type: shared.MsgChangePhase,
-			Data: map[string]interface{}{
-				"phase": string(battle.PhaseBattle),
-			},
-		})
-
-	case "main":
-		gc.sendMessage(shared.Message{
-			Type: shared.MsgChangePhase,
-			Data: map[string]interface{}{
-				"phase": string(battle.PhaseMain),
-			},
-		})
+		gc.sendAction(battle.Action{Type: battle.ActionChangePhase, Phase: battle.PhaseBattle})
+
+	case "main", "main2":
+		gc.sendAction(battle.Action{Type: battle.ActionChangePhase, Phase: battle.PhaseMain2})
 
 	case "end":
-		gc.sendMessage(shared.Message{
-			Type: shared.MsgEndTurn,
-		})
+		gc.sendAction(battle.Action{Type: battle.ActionEndTurn})
+
+	case "respond":
+		if len(args) < 1 {
+			fmt.Println(game.ColorRed + "Usage: respond [card]" + game.ColorReset)
+			return
+		}
+		ourPlayer := gc.getOurPlayer()
+		cards := append(append([]battle.Card(nil), ourPlayer.Hand...), ourPlayer.Traps...)
+		cardID, err := game.ResolveCard(cards, args[0])
+		if err != nil {
+			fmt.Println(game.ColorRed + "Invalid card: " + err.Error() + game.ColorReset)
+			return
+		}
+		gc.sendAction(battle.Action{Type: battle.ActionRespond, CardID: cardID})
+
+	case "pass":
+		gc.sendAction(battle.Action{Type: battle.ActionPass})
+
+	case "keep":
+		gc.sendMulligan(nil)
+
+	case "mulligan":
+		cardIDs, err := game.ResolveCards(gc.getOurPlayer().Hand, args)
+		if err != nil {
+			fmt.Println(game.ColorRed + "Invalid card: " + err.Error() + game.ColorReset)
+			return
+		}
+		gc.sendMulligan(cardIDs)
 
 	case "help":
 		// Help is already shown
 
-	case "quit":
-		gc.inGame = false
-		gc.gameState = nil
+	case "quit", "concede":
+		gc.sendAction(battle.Action{Type: battle.ActionConcede})
 	}
 }
 
@@ -307,7 +361,7 @@ func (gc *GameClient) displayGameState() {
 	fmt.Printf("Turn: %d | Phase: %s\n", state.TurnCount, state.Phase)
 
 	// Determine which player we are
-	var ourPlayer, opponentPlayer *battle.Player
+	var ourPlayer, opponentPlayer *battle.PlayerView
 	if playerNum == 1 {
 		ourPlayer = state.Player1
 		opponentPlayer = state.Player2
@@ -322,12 +376,17 @@ func (gc *GameClient) displayGameState() {
 	} else {
 		currentTurnIndicator = game.ColorRed + " (Opponent's turn)" + game.ColorReset
 	}
-	fmt.Printf("Current Turn: %s%s\n\n", state.CurrentTurn, currentTurnIndicator)
+	fmt.Printf("Current Turn: %s%s\n", state.CurrentTurn, currentTurnIndicator)
+	// Timed matches show the clocks as of the last update
+	if state.ClockHolder != "" {
+		fmt.Printf("%s (running: %s)\n", game.FormatClock(state.Rules, state.TurnTimeLeft, ourPlayer.TimeBank, ourPlayer.Timeouts), state.ClockHolder)
+	}
+	fmt.Println()
 
 	// Display Opponent
 	fmt.Printf("%s=== Opponent ===%s\n", game.ColorRed, game.ColorReset)
 	fmt.Printf("HP: %s%d%s | Mana: %d/%d\n", game.ColorRed, opponentPlayer.HP, game.ColorReset, opponentPlayer.Mana, opponentPlayer.MaxMana)
-	fmt.Printf("Hand: %d cards | Deck: %d cards\n", len(opponentPlayer.Hand), len(opponentPlayer.Deck))
+	fmt.Printf("Hand: %d cards | Deck: %d cards\n", opponentPlayer.HandCount, opponentPlayer.DeckCount)
 
 	fmt.Println("\nOpponent's Field:")
 	if len(opponentPlayer.Field) == 0 {
@@ -335,17 +394,20 @@ func (gc *GameClient) displayGameState() {
 	} else {
 		for i, card := range opponentPlayer.Field {
 			color := gc.getCardColor(card.Archetype)
-			fmt.Printf("  [%d] %s%s%s (%s) - ATK: %d / DEF: %d\n",
-				i, color, card.Name, game.ColorReset, card.Archetype, card.Attack, card.Defense)
+			fmt.Printf("  [%d] %s%s%s (%s, %s) - ATK: %d / DEF: %d%s\n",
+				i, color, card.Name, game.ColorReset, card.Archetype, card.InstanceID, card.Attack, card.Defense, cardTags(card))
 		}
 	}
+	if opponentPlayer.TrapCount > 0 {
+		fmt.Printf("  Face-down traps: %d\n", opponentPlayer.TrapCount)
+	}
 
 	fmt.Println("\n" + game.ColorWhite + "-------------------------------------" + game.ColorReset)
 
 	// Display Our Player
 	fmt.Printf("\n%s=== You ===%s\n", game.ColorGreen, game.ColorReset)
 	fmt.Printf("HP: %s%d%s | Mana: %d/%d\n", game.ColorGreen, ourPlayer.HP, game.ColorReset, ourPlayer.Mana, ourPlayer.MaxMana)
-	fmt.Printf("Deck: %d cards\n", len(ourPlayer.Deck))
+	fmt.Printf("Deck: %d cards\n", ourPlayer.DeckCount)
 
 	fmt.Println("\nYour Field:")
 	if len(ourPlayer.Field) == 0 {
@@ -353,8 +415,21 @@ func (gc *GameClient) displayGameState() {
 	} else {
 		for i, card := range ourPlayer.Field {
 			color := gc.getCardColor(card.Archetype)
-			fmt.Printf("  [%d] %s%s%s (%s) - ATK: %d / DEF: %d\n",
-				i, color, card.Name, game.ColorReset, card.Archetype, card.Attack, card.Defense)
+			status := ""
+			if card.HasAttacked {
+				status = game.ColorGray + " (exhausted)" + game.ColorReset
+			} else if card.SummoningSick(state.TurnCount, state.Rules) {
+				status = game.ColorGray + " (summoning sick)" + game.ColorReset
+			}
+			fmt.Printf("  [%d] %s%s%s (%s, %s) - ATK: %d / DEF: %d%s%s\n",
+				i, color, card.Name, game.ColorReset, card.Archetype, card.InstanceID, card.Attack, card.Defense, cardTags(card), status)
+		}
+	}
+
+	if len(ourPlayer.Traps) > 0 {
+		fmt.Println("\nYour Traps (face-down):")
+		for _, trap := range ourPlayer.Traps {
+			fmt.Printf("  %s %s - %s\n", trap.Name, trap.InstanceID, trap.Effect)
 		}
 	}
 
@@ -364,6 +439,8 @@ func (gc *GameClient) displayGameState() {
 		canPlay := ""
 		if card.Cost > ourPlayer.Mana {
 			canPlay = game.ColorRed + " (Not enough mana)" + game.ColorReset
+		} else if gc.canPlay(card.InstanceID) {
+			canPlay = game.ColorGreen + " (Playable)" + game.ColorReset
 		}
 
 		effectStr := ""
@@ -371,8 +448,24 @@ func (gc *GameClient) displayGameState() {
 			effectStr = fmt.Sprintf(" - %s", card.Effect)
 		}
 
-		fmt.Printf("  [%d] %s%s%s (Cost: %d) - ATK: %d / DEF: %d%s%s\n",
-			i, color, card.Name, game.ColorReset, card.Cost, card.Attack, card.Defense, effectStr, canPlay)
+		statsStr := fmt.Sprintf("ATK: %d / DEF: %d", card.Attack, card.Defense)
+		switch card.Type {
+		case battle.CardSpell, battle.CardTrap, battle.CardInstant:
+			statsStr = fmt.Sprintf("[%s]", card.Type)
+		case battle.CardEquipment:
+			statsStr = fmt.Sprintf("[%s] +%d ATK / +%d DEF", card.Type, card.Attack, card.Defense)
+		}
+
+		fmt.Printf("  [%d] %s%s%s %s (Cost: %d) - %s%s%s%s\n",
+			i, color, card.Name, game.ColorReset, card.InstanceID, card.Cost, statsStr, cardTags(card), effectStr, canPlay)
+	}
+
+	if len(state.Stack) > 0 {
+		fmt.Printf("\nStack (%s has priority):\n", state.Priority)
+		for i := len(state.Stack) - 1; i >= 0; i-- {
+			item := state.Stack[i]
+			fmt.Printf("  %s: %s (%s)\n", item.PlayerID, item.Card.Name, item.Action)
+		}
 	}
 
 	if state.LastAction != "" {
@@ -385,14 +478,24 @@ func (gc *GameClient) displayGameState() {
 // handleMessages handles messages from the server
 func (gc *GameClient) handleMessages() {
 	for gc.connected {
-		var msg shared.Message
-		err := gc.conn.ReadJSON(&msg)
+		messageType, data, err := gc.conn.ReadMessage()
 		if err != nil {
 			fmt.Printf("\n%sDisconnected from server%s\n", game.ColorRed, game.ColorReset)
 			gc.connected = false
 			return
 		}
 
+		// Binary frames carry game updates
+		if messageType == websocket.BinaryMessage {
+			gc.handleBinaryUpdate(data)
+			continue
+		}
+
+		var msg shared.Message
+		if err := json.Unmarshal(data, &msg); err != nil {
+			fmt.Printf("\n%sInvalid message from server: %v%s\n", game.ColorRed, err, game.ColorReset)
+			continue
+		}
 		gc.processServerMessage(msg)
 	}
 }
@@ -407,6 +510,9 @@ func (gc *GameClient) processServerMessage(msg shared.Message) {
 	case shared.MsgNameSet:
 		// Name confirmed
 
+	case shared.MsgCatalog:
+		gc.handleCatalog(msg)
+
 	case shared.MsgQueueJoined:
 		gc.inQueue = true
 		data := msg.Data.(map[string]interface{})
@@ -425,6 +531,13 @@ func (gc *GameClient) processServerMessage(msg shared.Message) {
 	case shared.MsgGameOver:
 		gc.handleGameOver(msg)
 
+	case shared.MsgMulliganDone:
+		data := msg.Data.(map[string]interface{})
+		fmt.Printf("\n%sRedrew %d cards%s\n", game.ColorGreen, int(data["redrawn"].(float64)), game.ColorReset)
+		if waiting, _ := data["waiting"].(bool); waiting {
+			fmt.Println(game.ColorYellow + "Waiting for opponent to mulligan..." + game.ColorReset)
+		}
+
 	case shared.MsgError:
 		data := msg.Data.(map[string]interface{})
 		fmt.Printf("\n%sError: %s%s\n", game.ColorRed, data["error"], game.ColorReset)
@@ -448,8 +561,14 @@ func (gc *GameClient) handleGameStart(msg shared.Message) {
 	opponentName := data["opponentName"].(string)
 
 	// Convert game state
-	stateData := data["gameState"].(map[string]interface{})
-	gc.gameState = shared.ConvertToGameState(stateData)
+	view, err := shared.ConvertToGameView(data["gameState"])
+	if err != nil {
+		fmt.Printf("\n%sInvalid game state: %v%s\n", game.ColorRed, err, game.ColorReset)
+		return
+	}
+	gc.gameState = view
+	gc.legal, _ = shared.ConvertToActions(data["legalActions"])
+	gc.desyncSeq = -1
 
 	gc.inGame = true
 	gc.inQueue = false
@@ -460,33 +579,116 @@ func (gc *GameClient) handleGameStart(msg shared.Message) {
 	gc.input.ReadString('\n')
 }
 
+// handleCatalog builds the codec for binary updates from the server's cards
+func (gc *GameClient) handleCatalog(msg shared.Message) {
+	data := msg.Data.(map[string]interface{})
+	cards, err := shared.ConvertToCards(data["cards"])
+	var codec *battle.Codec
+	if err == nil {
+		codec, err = battle.NewCodec(cards)
+	}
+	if err != nil {
+		fmt.Printf("\n%sInvalid card catalog: %v%s\n", game.ColorRed, err, game.ColorReset)
+		return
+	}
+
+	gc.mu.Lock()
+	gc.codec = codec
+	gc.mu.Unlock()
+}
+
 // handleGameUpdate handles game state update
 func (gc *GameClient) handleGameUpdate(msg shared.Message) {
 	data := msg.Data.(map[string]interface{})
-	stateData := data["gameState"].(map[string]interface{})
+	view, err := shared.ConvertToGameView(data["gameState"])
+	if err != nil {
+		fmt.Printf("\n%sInvalid game state: %v%s\n", game.ColorRed, err, game.ColorReset)
+		return
+	}
 
+	legal, _ := shared.ConvertToActions(data["legalActions"])
+
+	if expected, ok := data["viewHash"].(string); ok {
+		seq, _ := data["seq"].(float64)
+		gc.checkSync(int(seq), expected, view)
+	}
+	gc.setView(view, legal)
+}
+
+// handleBinaryUpdate handles a game state update in the binary encoding
+func (gc *GameClient) handleBinaryUpdate(data []byte) {
+	gc.mu.RLock()
+	codec := gc.codec
+	gc.mu.RUnlock()
+
+	if codec == nil {
+		fmt.Printf("\n%sGame update arrived before the card catalog%s\n", game.ColorRed, game.ColorReset)
+		return
+	}
+	update, err := codec.DecodeUpdate(data)
+	if err != nil {
+		fmt.Printf("\n%sInvalid game state: %v%s\n", game.ColorRed, err, game.ColorReset)
+		return
+	}
+
+	gc.checkSync(update.View.Version, update.ViewHash, update.View)
+	gc.setView(update.View, update.Legal)
+}
+
+// setView stores the latest view of the game and the moves open to us
+func (gc *GameClient) setView(view *battle.GameView, legal []battle.Action) {
 	gc.mu.Lock()
-	gc.gameState = shared.ConvertToGameState(stateData)
+	gc.gameState = view
+	gc.legal = legal
 	gc.mu.Unlock()
 }
 
+// checkSync compares the hash of a decoded view with the one the server
+// sent, reporting a mismatch to the server. Each seq is reported at most
+// once, so a resent view that still differs does not report again.
+func (gc *GameClient) checkSync(seq int, expected string, view *battle.GameView) {
+	actual := view.Hash()
+	if actual == expected || seq <= gc.desyncSeq {
+		return
+	}
+	gc.desyncSeq = seq
+
+	fmt.Printf("\n%sState out of sync with the server at seq %d, resyncing...%s\n", game.ColorYellow, seq, game.ColorReset)
+	gc.sendMessage(shared.Message{
+		Type: shared.MsgDesync,
+		Data: map[string]interface{}{
+			"gameID":   gc.gameID,
+			"seq":      seq,
+			"expected": expected,
+			"actual":   actual,
+		},
+	})
+}
+
 // handleGameOver handles game over message
 func (gc *GameClient) handleGameOver(msg shared.Message) {
 	data := msg.Data.(map[string]interface{})
 	winnerID := data["winner"].(string)
 	winnerName := data["winnerName"].(string)
+	draw, _ := data["draw"].(bool)
+	reason, _ := data["reason"].(string)
 
 	gc.display.ClearScreen()
 	fmt.Println(game.ColorCyan + "=====================================" + game.ColorReset)
 	fmt.Println(game.ColorCyan + "           GAME OVER!              " + game.ColorReset)
 	fmt.Println(game.ColorCyan + "=====================================" + game.ColorReset)
 
-	if (gc.playerNum == 1 && winnerID == gc.gameState.Player1.ID) ||
+	if draw {
+		fmt.Printf("\n%sThe match is a draw!%s\n", game.ColorYellow, game.ColorReset)
+	} else if (gc.playerNum == 1 && winnerID == gc.gameState.Player1.ID) ||
 		(gc.playerNum == 2 && winnerID == gc.gameState.Player2.ID) {
 		fmt.Printf("\n%sCongratulations! You won!%s\n", game.ColorGreen, game.ColorReset)
 	} else {
 		fmt.Printf("\n%s%s wins!%s\n", game.ColorRed, winnerName, game.ColorReset)
 	}
+	if reason != "" {
+		fmt.Printf("%sReason: %s%s\n", game.ColorGray, battle.EndReason(reason).Description(), game.ColorReset)
+	}
 
 	fmt.Println("\nFinal Stats:")
 	fmt.Printf("Your HP: %d\n", gc.getOurPlayer().HP)
@@ -508,14 +710,73 @@ func (gc *GameClient) sendMessage(msg shared.Message) {
 	}
 }
 
-func (gc *GameClient) getOurPlayer() *battle.Player {
+// canPlay reports whether the server listed any way to play the card,
+// including as a response
+func (gc *GameClient) canPlay(cardID string) bool {
+	gc.mu.RLock()
+	defer gc.mu.RUnlock()
+
+	for _, legal := range gc.legal {
+		if (legal.Type == battle.ActionPlayCard || legal.Type == battle.ActionRespond) && legal.CardID == cardID {
+			return true
+		}
+	}
+	return false
+}
+
+// cardTags lists a field card's keywords, attached equipment and modifiers
+func cardTags(card battle.Card) string {
+	tags := ""
+	if keywords := game.FormatKeywords(card); keywords != "" {
+		tags += " " + keywords
+	}
+	for _, equipment := range card.Attached {
+		tags += " +" + equipment.Name
+	}
+	for _, modifier := range card.Modifiers {
+		tags += " <" + modifier.String() + ">"
+	}
+	return tags
+}
+
+func (gc *GameClient) sendAction(action battle.Action) {
+	gc.mu.RLock()
+	binary := gc.codec != nil
+	gc.mu.RUnlock()
+
+	if binary {
+		if err := gc.conn.WriteMessage(websocket.BinaryMessage, battle.EncodeAction(action)); err != nil {
+			fmt.Printf("\n%sError sending message: %v%s\n", game.ColorRed, err, game.ColorReset)
+		}
+		return
+	}
+	gc.sendMessage(shared.Message{
+		Type: shared.MsgAction,
+		Data: action,
+	})
+}
+
+// sendMulligan shuffles the given hand cards back, or keeps the hand when there are none
+func (gc *GameClient) sendMulligan(cardIDs []string) {
+	if cardIDs == nil {
+		cardIDs = []string{}
+	}
+	gc.sendMessage(shared.Message{
+		Type: shared.MsgMulligan,
+		Data: map[string]interface{}{
+			"cardIDs": cardIDs,
+		},
+	})
+}
+
+func (gc *GameClient) getOurPlayer() *battle.PlayerView {
 	if gc.playerNum == 1 {
 		return gc.gameState.Player1
 	}
 	return gc.gameState.Player2
 }
 
-func (gc *GameClient) getOpponentPlayer() *battle.Player {
+func (gc *GameClient) getOpponentPlayer() *battle.PlayerView {
 	if gc.playerNum == 1 {
 		return gc.gameState.Player2
 	}
@@ -1,24 +1,75 @@
 package main
 
 import (
+	"cardgame/battle"
 	"cardgame/server"
+	"context"
 	"flag"
 	"fmt"
 	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 )
 
+// shutdownTimeout is how long running matches get to stop on exit
+const shutdownTimeout = 5 * time.Second
+
 func main() {
 	// Parse command line flags
 	port := flag.String("port", "8080", "Server port")
+	rulesName := flag.String("rules", battle.RulesStandard, "Rule preset (standard, classic)")
+
+	// The presets are untimed; online matches get the server's time controls
+	responseTime := flag.Duration("response-timeout", server.DefaultResponseTime, "Time a player has to respond before passing automatically (0 for none)")
+	turnTime := flag.Duration("turn-time", server.DefaultTurnTime, "Time limit per turn before it ends automatically (0 for none)")
+	matchTime := flag.Duration("match-time", 0, "Chess clock time each player has for the whole match (0 for none)")
+	maxTimeouts := flag.Int("max-timeouts", server.DefaultMaxTimeouts, "Turn timeouts after which a player forfeits (0 for never)")
+	matchTTL := flag.Duration("match-ttl", server.DefaultMatchTTL, "Idle time after which a match is evicted")
 	flag.Parse()
 
+	rules, err := battle.RulesPreset(*rulesName)
+	if err != nil {
+		log.Fatal("Invalid rules:", err)
+	}
+	rules.ResponseTime = *responseTime
+	rules.TurnTime = *turnTime
+	rules.MatchTime = *matchTime
+	rules.MaxTimeouts = *maxTimeouts
+	if err := rules.Validate(); err != nil {
+		log.Fatal("Invalid time controls:", err)
+	}
+
 	// Create and start server
-	gameServer := server.NewGameServer(*port)
+	gameServer, err := server.NewGameServer(*port)
+	if err != nil {
+		log.Fatal("Server error:", err)
+	}
+	gameServer.SetRules(rules)
+	gameServer.SetMatchTTL(*matchTTL)
 
-	fmt.Printf("🎮 Card Battle Game Server starting on port %s...\n", *port)
+	fmt.Printf("🎮 Card Battle Game Server starting on port %s (%s rules)...\n", *port, rules.Name)
 	fmt.Println("Players can connect using: go run cmd/client/main.go -server localhost:" + *port)
 
-	if err := gameServer.Start(); err != nil {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	errs := make(chan error, 1)
+	go func() {
+		errs <- gameServer.Start()
+	}()
+
+	select {
+	case err := <-errs:
 		log.Fatal("Server error:", err)
+	case <-ctx.Done():
+	}
+
+	fmt.Println("Shutting down...")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := gameServer.Shutdown(shutdownCtx); err != nil {
+		log.Println("Shutdown error:", err)
 	}
 }
@@ -22,30 +22,6 @@ const (
 	ColorBoldCyan   = "\033[1;36m"
 )
 
-// Game constants
-const (
-	StartingHP       = 8000
-	StartingMana     = 1
-	MaxMana          = 10
-	StartingHandSize = 5
-	DeckSize         = 40
-	MaxFieldSize     = 5
-	MaxHandSize      = 10
-
-	// Damage/Heal amounts for effects
-	LightningDamage = 1500
-	HealingAmount   = 1000
-	BurnDamage      = 500
-
-	// Card draw amounts
-	DrawCardEffect = 1
-	DrawTwoEffect  = 2
-
-	// Mana gain
-	ManaGainEffect  = 1
-	ManaBoostEffect = 2
-)
-
 // Timing constants
 const (
 	AIThinkDelay   = 1 * time.Second
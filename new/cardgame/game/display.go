@@ -3,15 +3,24 @@ package game
 import (
 	"fmt"
 	"strings"
+	"time"
 	"cardgame/battle"
 )
 
 // Display handles all game display functions
-type Display struct{}
+type Display struct {
+	rules battle.RuleSet
+	turn  int
+}
 
 // NewDisplay creates a new display handler
 func NewDisplay() *Display {
-	return &Display{}
+	return &Display{rules: battle.StandardRules()}
+}
+
+// SetRules sets the rules shown on the welcome screen and used for the HP bars
+func (d *Display) SetRules(rules battle.RuleSet) {
+	d.rules = rules
 }
 
 // ClearScreen clears the terminal screen
@@ -24,13 +33,54 @@ func (d *Display) ShowWelcome() {
 	d.ClearScreen()
 	d.ShowBanner()
 	fmt.Println("\nGame Rules:")
-	fmt.Println("• Each deck has 40 cards")
-	fmt.Println("• You start with 5 cards in hand") 
+	if d.rules.DeckSize > 0 {
+		fmt.Printf("• Each deck has %d cards\n", d.rules.DeckSize)
+	}
+	fmt.Printf("• You start with %d cards in hand and %d HP\n", d.rules.OpeningHand, d.rules.StartingHP)
+	if d.rules.Mulligan {
+		fmt.Println("• Before the first turn you may shuffle any of those cards back and redraw them once")
+	}
 	fmt.Println("• Draw 1 card each turn")
+	if d.rules.ManaPerTurn > 0 {
+		fmt.Printf("• Max mana grows by %d each turn, up to %d\n", d.rules.ManaPerTurn, d.rules.MaxMana)
+	}
+	if d.rules.MaxFieldSize > 0 {
+		fmt.Printf("• Up to %d cards on the field\n", d.rules.MaxFieldSize)
+	}
+	if d.rules.TurnTime > 0 {
+		fmt.Printf("• Each turn lasts at most %s\n", formatDuration(d.rules.TurnTime))
+	}
+	if d.rules.ResponseTime > 0 {
+		fmt.Printf("• You have %s to respond to your opponent's moves\n", formatDuration(d.rules.ResponseTime))
+	}
 	fmt.Println("• Reduce opponent's HP to 0 to win!")
+	fmt.Println("\nKeywords:")
+	for _, keyword := range battle.AllKeywords {
+		fmt.Printf("• %s%s%s - %s\n", ColorYellow, KeywordName(keyword), ColorReset, keyword.Description())
+	}
+	fmt.Println("\nArchetypes:")
+	for _, def := range battle.RegisteredArchetypes() {
+		fmt.Printf("• %s%s%s - %s", d.getCardColor(def.ID), def.Name, ColorReset, def.Description)
+		if len(def.Synergies) > 0 {
+			fmt.Printf(" (%s)", def.Describe(d.rules))
+		}
+		fmt.Println()
+	}
 	fmt.Println("\nDeck Types:")
-	fmt.Println("1. " + ColorYellow + "Egyptian Gods" + ColorReset + " (Attack focused - +10% ATK per Egyptian)")
-	fmt.Println("2. " + ColorBlue + "Greek Gods" + ColorReset + " (Defense focused - +10% DEF per Greek)")
+	for i, archetype := range deckArchetypes {
+		fmt.Printf("%d. %s%s%s\n", i+1, d.getCardColor(archetype), ArchetypeName(archetype), ColorReset)
+	}
+}
+
+// deckArchetypes are the archetypes of the decks offered by GetDeckChoice, in menu order
+var deckArchetypes = []battle.Archetype{battle.ArchetypeEgyptian, battle.ArchetypeGreek}
+
+// ArchetypeName returns the registered display name of an archetype
+func ArchetypeName(archetype battle.Archetype) string {
+	if def, ok := battle.LookupArchetype(archetype); ok {
+		return def.Name
+	}
+	return string(archetype)
 }
 
 // ShowBanner displays the game banner
@@ -42,6 +92,8 @@ func (d *Display) ShowBanner() {
 
 // ShowGameState displays the current game state
 func (d *Display) ShowGameState(game *battle.GameState) {
+	d.rules = game.Rules
+	d.turn = game.TurnCount
 	d.ClearScreen()
 	d.ShowBanner()
 	d.ShowTurnInfo(game)
@@ -50,6 +102,7 @@ func (d *Display) ShowGameState(game *battle.GameState) {
 	d.ShowOpponent(game.Player2)
 	fmt.Println("\n" + ColorWhite + ThinDivider + ColorReset)
 	d.ShowPlayer(game.Player1)
+	d.ShowStack(game)
 	
 	// Show last action
 	if game.LastAction != "" {
@@ -59,6 +112,41 @@ func (d *Display) ShowGameState(game *battle.GameState) {
 	fmt.Println("\n" + ColorCyan + Divider + ColorReset)
 }
 
+// FormatClock describes the time controls of a timed match in one line:
+// what is left of the turn, a player's time bank and their timeouts so far
+func FormatClock(rules battle.RuleSet, turnLeft, bank time.Duration, timeouts int) string {
+	var parts []string
+	if rules.TurnTime > 0 {
+		parts = append(parts, "Turn time: "+formatDuration(turnLeft))
+	}
+	if rules.MatchTime > 0 {
+		parts = append(parts, "Time bank: "+formatDuration(bank))
+	}
+	if rules.MaxTimeouts > 0 {
+		parts = append(parts, fmt.Sprintf("Timeouts: %d/%d", timeouts, rules.MaxTimeouts))
+	}
+	return strings.Join(parts, " | ")
+}
+
+// formatDuration formats a duration as minutes and seconds
+func formatDuration(d time.Duration) string {
+	seconds := int(d.Round(time.Second) / time.Second)
+	return fmt.Sprintf("%d:%02d", seconds/60, seconds%60)
+}
+
+// ShowStack displays the moves waiting to resolve, the top one first
+func (d *Display) ShowStack(game *battle.GameState) {
+	if len(game.Stack) == 0 {
+		return
+	}
+	
+	fmt.Printf("\n%sStack%s (%s has priority):\n", ColorBoldCyan, ColorReset, game.Priority)
+	for i := len(game.Stack) - 1; i >= 0; i-- {
+		item := game.Stack[i]
+		fmt.Printf("  %s%s%s: %s (%s)\n", ColorYellow, item.PlayerID, ColorReset, item.Card.Name, item.Action)
+	}
+}
+
 // ShowTurnInfo displays turn and phase information
 func (d *Display) ShowTurnInfo(game *battle.GameState) {
 	phaseColor := d.getPhaseColor(game.Phase)
@@ -66,6 +154,9 @@ func (d *Display) ShowTurnInfo(game *battle.GameState) {
 		ColorBoldCyan, game.TurnCount, ColorReset,
 		phaseColor, game.Phase, ColorReset,
 		ColorYellow, game.CurrentTurn, ColorReset)
+	if game.ClockHolder != "" {
+		fmt.Printf("%s⏱ %s%s\n", ColorGray, FormatClock(game.Rules, game.TurnTimeLeft, game.Player1.TimeBank, game.Player1.Timeouts), ColorReset)
+	}
 }
 
 // ShowOpponent displays opponent information
@@ -73,6 +164,9 @@ func (d *Display) ShowOpponent(opponent *battle.Player) {
 	fmt.Printf("\n%s═══ %s (Opponent) ═══%s\n", ColorRed, opponent.ID, ColorReset)
 	d.ShowPlayerStats(opponent, ColorRed)
 	d.ShowField("Opponent's Field:", opponent.Field, false)
+	if len(opponent.Traps) > 0 {
+		fmt.Printf("%s  Face-down traps: %d%s\n", ColorGray, len(opponent.Traps), ColorReset)
+	}
 }
 
 // ShowPlayer displays player information
@@ -80,12 +174,25 @@ func (d *Display) ShowPlayer(player *battle.Player) {
 	fmt.Printf("\n%s═══ %s (You) ═══%s\n", ColorGreen, player.ID, ColorReset)
 	d.ShowPlayerStats(player, ColorGreen)
 	d.ShowField("Your Field:", player.Field, true)
+	d.ShowTraps(player.Traps)
 	d.ShowHand(player)
 }
 
+// ShowTraps displays the player's own face-down traps
+func (d *Display) ShowTraps(traps []battle.Card) {
+	if len(traps) == 0 {
+		return
+	}
+	
+	fmt.Println("\nYour Traps (face-down):")
+	for _, trap := range traps {
+		fmt.Printf("  %s%s%s %s%s - %s%s\n", ColorPurple, trap.Name, ColorReset, ColorGray, trap.InstanceID, trap.Effect, ColorReset)
+	}
+}
+
 // ShowPlayerStats displays HP and Mana
 func (d *Display) ShowPlayerStats(player *battle.Player, color string) {
-	hpBar := d.createHPBar(player.HP, StartingHP)
+	hpBar := d.createHPBar(player.HP, d.rules.StartingHP)
 	manaBar := d.createManaBar(player.Mana, player.MaxMana)
 	
 	fmt.Printf("HP: %s%s%s %s\n", color, hpBar, ColorReset, d.formatHP(player.HP))
@@ -116,19 +223,56 @@ func (d *Display) ShowFieldCard(card battle.Card, index int, showIndex bool) {
 		indexStr = fmt.Sprintf("[%d] ", index)
 	}
 	
-	fmt.Printf("  %s%s%s%s%s (%s) - ATK: %s%d%s / DEF: %s%d%s",
+	fmt.Printf("  %s%s%s%s %s(%s, %s)%s - ATK: %s%d%s / DEF: %s%d%s",
 		indexStr,
 		color, card.Name, ColorReset,
-		ColorGray, card.Archetype, ColorReset,
+		ColorGray, card.Archetype, card.InstanceID, ColorReset,
 		ColorBoldRed, card.Attack, ColorReset,
 		ColorBoldBlue, card.Defense, ColorReset)
 	
+	if keywords := FormatKeywords(card); keywords != "" {
+		fmt.Printf(" %s%s%s", ColorYellow, keywords, ColorReset)
+	}
+	
 	if card.Effect != "" {
 		fmt.Printf(" %s[%s]%s", ColorPurple, card.Effect, ColorReset)
 	}
+	
+	for _, equipment := range card.Attached {
+		fmt.Printf(" %s+%s%s", ColorCyan, equipment.Name, ColorReset)
+	}
+	
+	for _, modifier := range card.Modifiers {
+		fmt.Printf(" %s<%s>%s", d.getModifierColor(modifier.Kind), modifier, ColorReset)
+	}
+	
+	if card.HasAttacked {
+		fmt.Printf(" %s(exhausted)%s", ColorGray, ColorReset)
+	} else if card.SummoningSick(d.turn, d.rules) {
+		fmt.Printf(" %s(summoning sick)%s", ColorGray, ColorReset)
+	}
 	fmt.Println()
 }
 
+// FormatKeywords lists a card's keywords as "{Guard, Stealth}", or returns
+// an empty string for a card without any
+func FormatKeywords(card battle.Card) string {
+	if len(card.Keywords) == 0 {
+		return ""
+	}
+	
+	names := make([]string, len(card.Keywords))
+	for i, keyword := range card.Keywords {
+		names[i] = KeywordName(keyword)
+	}
+	return "{" + strings.Join(names, ", ") + "}"
+}
+
+// KeywordName returns the capitalized name of a keyword
+func KeywordName(keyword battle.Keyword) string {
+	return strings.ToUpper(string(keyword[:1])) + string(keyword[1:])
+}
+
 // ShowHand displays the player's hand
 func (d *Display) ShowHand(player *battle.Player) {
 	fmt.Println("\nYour Hand:")
@@ -153,11 +297,24 @@ func (d *Display) ShowHandCard(card battle.Card, index int, playerMana int) {
 		playable = ColorRed + " (Not enough mana)" + ColorReset
 	}
 	
-	fmt.Printf("  [%d] %s%s%s (Cost: %s%d%s) - ATK: %d / DEF: %d",
+	fmt.Printf("  [%d] %s%s%s %s%s%s (Cost: %s%d%s)",
 		index,
 		color, card.Name, ColorReset,
-		costColor, card.Cost, ColorReset,
-		card.Attack, card.Defense)
+		ColorGray, card.InstanceID, ColorReset,
+		costColor, card.Cost, ColorReset)
+	
+	switch card.Type {
+	case battle.CardSpell, battle.CardTrap, battle.CardInstant:
+		fmt.Printf(" %s[%s]%s", ColorCyan, card.Type, ColorReset)
+	case battle.CardEquipment:
+		fmt.Printf(" %s[%s]%s +%d ATK / +%d DEF", ColorCyan, card.Type, ColorReset, card.Attack, card.Defense)
+	default:
+		fmt.Printf(" - ATK: %d / DEF: %d", card.Attack, card.Defense)
+	}
+	
+	if keywords := FormatKeywords(card); keywords != "" {
+		fmt.Printf(" %s%s%s", ColorYellow, keywords, ColorReset)
+	}
 	
 	if card.Effect != "" {
 		fmt.Printf(" - %s%s%s", ColorPurple, card.Effect, ColorReset)
@@ -180,14 +337,19 @@ func (d *Display) ShowCommands(phase battle.GamePhase, isPlayerTurn bool) {
 		fmt.Println("  " + ColorGreen + "draw" + ColorReset + "     - Draw a card")
 		
 	case battle.PhaseMain:
-		fmt.Println("  " + ColorGreen + "play [n]" + ColorReset + " - Play card number n from hand")
+		fmt.Println("  " + ColorGreen + "play [n]" + ColorReset + " - Play card number n (or its ID) from hand")
+		fmt.Println("  " + ColorGray + "           (equipment: play [n] [field card])" + ColorReset)
 		fmt.Println("  " + ColorGreen + "battle" + ColorReset + "   - Enter battle phase")
 		fmt.Println("  " + ColorGreen + "end" + ColorReset + "      - End your turn")
 		
 	case battle.PhaseBattle:
 		fmt.Println("  " + ColorGreen + "attack [attacker] [target]" + ColorReset + " - Attack with your card")
-		fmt.Println("  " + ColorGray + "                            (use -1 for direct attack)" + ColorReset)
-		fmt.Println("  " + ColorGreen + "main" + ColorReset + "     - Return to main phase")
+		fmt.Println("  " + ColorGray + "                            (card numbers or IDs, -1 or direct for direct attack)" + ColorReset)
+		fmt.Println("  " + ColorGreen + "main2" + ColorReset + "    - Enter the second main phase")
+		fmt.Println("  " + ColorGreen + "end" + ColorReset + "      - End your turn")
+		
+	case battle.PhaseMain2:
+		fmt.Println("  " + ColorGreen + "play [n]" + ColorReset + " - Play card number n (or its ID) from hand")
 		fmt.Println("  " + ColorGreen + "end" + ColorReset + "      - End your turn")
 		
 	case battle.PhaseEnd:
@@ -195,22 +357,60 @@ func (d *Display) ShowCommands(phase battle.GamePhase, isPlayerTurn bool) {
 	}
 	
 	fmt.Println("  " + ColorGreen + "help" + ColorReset + "     - Show this help")
-	fmt.Println("  " + ColorGreen + "quit" + ColorReset + "     - Exit the game")
+	fmt.Println("  " + ColorGreen + "quit" + ColorReset + "     - Concede and exit the game")
+}
+
+// ShowResponseCommands displays the commands available while holding priority
+func (d *Display) ShowResponseCommands() {
+	fmt.Println("\n" + ColorBoldCyan + "Respond or Pass:" + ColorReset)
+	fmt.Println("  " + ColorGreen + "respond [n]" + ColorReset + " - Respond with an instant or a set trap (hand number or card ID)")
+	fmt.Println("  " + ColorGreen + "pass" + ColorReset + "        - Let the top of the stack resolve")
+	fmt.Println("  " + ColorGreen + "quit" + ColorReset + "        - Concede and exit the game")
+}
+
+// ShowMulliganCommands displays the commands available before the first turn
+func (d *Display) ShowMulliganCommands() {
+	fmt.Println("\n" + ColorBoldCyan + "Mulligan:" + ColorReset)
+	fmt.Println("  " + ColorGreen + "keep" + ColorReset + "              - Keep your opening hand")
+	fmt.Println("  " + ColorGreen + "mulligan [n ...]" + ColorReset + "  - Shuffle the listed hand cards back and redraw as many")
+	fmt.Println("  " + ColorGreen + "quit" + ColorReset + "              - Concede and exit the game")
+}
+
+// ShowLegalActions lists the moves the engine currently accepts
+func (d *Display) ShowLegalActions(actions []battle.Action) {
+	if len(actions) == 0 {
+		return
+	}
+	
+	moves := make([]string, len(actions))
+	for i, action := range actions {
+		moves[i] = action.String()
+	}
+	fmt.Printf("\n%sLegal moves:%s %s\n", ColorBoldCyan, ColorReset, strings.Join(moves, " | "))
 }
 
 // ShowGameOver displays the game over screen
 func (d *Display) ShowGameOver(game *battle.GameState) {
+	d.rules = game.Rules
 	d.ClearScreen()
 	fmt.Println(ColorCyan + "╔═══════════════════════════════════╗" + ColorReset)
 	fmt.Println(ColorCyan + "║" + ColorBoldRed + "         GAME OVER!              " + ColorCyan + "║" + ColorReset)
 	fmt.Println(ColorCyan + "╚═══════════════════════════════════╝" + ColorReset)
 	
-	winnerColor := ColorGreen
-	if game.Winner == "AI" || game.Winner == game.Player2.ID {
-		winnerColor = ColorRed
+	if game.Result != nil && game.Result.Draw {
+		fmt.Printf("\n%s🤝 Draw! 🤝%s\n", ColorYellow, ColorReset)
+	} else {
+		winnerColor := ColorGreen
+		if game.Winner == "AI" || game.Winner == game.Player2.ID {
+			winnerColor = ColorRed
+		}
+		
+		fmt.Printf("\n%s🏆 Winner: %s! 🏆%s\n", winnerColor, game.Winner, ColorReset)
 	}
 	
-	fmt.Printf("\n%s🏆 Winner: %s! 🏆%s\n", winnerColor, game.Winner, ColorReset)
+	if game.Result != nil {
+		fmt.Printf("%s%s%s\n", ColorGray, game.Result.Summary(), ColorReset)
+	}
 	
 	fmt.Println("\n" + ColorBoldCyan + "Final Statistics:" + ColorReset)
 	fmt.Printf("├─ Your HP: %s%d%s\n", d.getHPColor(game.Player1.HP), game.Player1.HP, ColorReset)
@@ -245,11 +445,21 @@ func (d *Display) getCardColor(archetype battle.Archetype) string {
 	}
 }
 
+func (d *Display) getModifierColor(kind battle.ModifierKind) string {
+	switch kind {
+	case battle.ModifierBuff, battle.ModifierShield:
+		return ColorGreen
+	case battle.ModifierDebuff, battle.ModifierPoison, battle.ModifierStun:
+		return ColorRed
+	}
+	return ColorGray
+}
+
 func (d *Display) getPhaseColor(phase battle.GamePhase) string {
 	switch phase {
 	case battle.PhaseDrawn:
 		return ColorCyan
-	case battle.PhaseMain:
+	case battle.PhaseMain, battle.PhaseMain2:
 		return ColorGreen
 	case battle.PhaseBattle:
 		return ColorRed
@@ -261,9 +471,10 @@ func (d *Display) getPhaseColor(phase battle.GamePhase) string {
 }
 
 func (d *Display) getHPColor(hp int) string {
-	if hp > StartingHP*0.7 {
+	startingHP := float64(d.rules.StartingHP)
+	if float64(hp) > startingHP*0.7 {
 		return ColorGreen
-	} else if hp > StartingHP*0.3 {
+	} else if float64(hp) > startingHP*0.3 {
 		return ColorYellow
 	}
 	return ColorRed
@@ -271,7 +482,7 @@ func (d *Display) getHPColor(hp int) string {
 
 func (d *Display) formatHP(hp int) string {
 	color := d.getHPColor(hp)
-	return fmt.Sprintf("%s%d/%d%s", color, hp, StartingHP, ColorReset)
+	return fmt.Sprintf("%s%d/%d%s", color, hp, d.rules.StartingHP, ColorReset)
 }
 
 func (d *Display) createHPBar(current, max int) string {
@@ -27,13 +27,48 @@ func (ai *AIPlayer) MakeDecision(game *battle.GameState, engine *battle.BattleEn
 		ai.handleMainPhase(game, engine, aiPlayerID)
 	case battle.PhaseBattle:
 		ai.handleBattlePhase(game, engine, aiPlayerID)
+	default:
+		engine.Apply(game.ID, aiPlayerID, battle.Action{Type: battle.ActionEndTurn})
 	}
 }
 
+// Respond answers a move on the stack. The AI uses the first response it
+// has and passes when it has none.
+func (ai *AIPlayer) Respond(game *battle.GameState, engine *battle.BattleEngine, aiPlayerID string) {
+	time.Sleep(AIThinkDelay)
+
+	legal, _ := engine.LegalActions(game.ID, aiPlayerID)
+	for _, action := range legal {
+		if action.Type == battle.ActionRespond {
+			engine.Apply(game.ID, aiPlayerID, action)
+			return
+		}
+	}
+	engine.Apply(game.ID, aiPlayerID, battle.Action{Type: battle.ActionPass})
+}
+
+// Mulligan shuffles back every card in the opening hand the AI could not
+// afford within its first three turns
+func (ai *AIPlayer) Mulligan(game *battle.GameState, engine *battle.BattleEngine, aiPlayerID string) {
+	time.Sleep(AIThinkDelay)
+
+	aiPlayer := ai.getAIPlayer(game, aiPlayerID)
+	rules := game.Rules
+	affordable := min(rules.StartingMaxMana+2*rules.ManaPerTurn, rules.MaxMana)
+
+	var cardIDs []string
+	for _, card := range aiPlayer.Hand {
+		if card.Cost > affordable {
+			cardIDs = append(cardIDs, card.InstanceID)
+		}
+	}
+	engine.Apply(game.ID, aiPlayerID, battle.Action{Type: battle.ActionMulligan, CardIDs: cardIDs})
+}
+
 // handleDrawPhase handles AI decisions during draw phase
 func (ai *AIPlayer) handleDrawPhase(game *battle.GameState, engine *battle.BattleEngine, aiPlayerID string) {
 	// Always draw
-	engine.DrawCard(game.ID, aiPlayerID)
+	engine.Apply(game.ID, aiPlayerID, battle.Action{Type: battle.ActionDraw})
 }
 
 // handleMainPhase handles AI decisions during main phase
@@ -44,80 +79,85 @@ func (ai *AIPlayer) handleMainPhase(game *battle.GameState, engine *battle.Battl
 	cardsPlayed := 0
 	maxCardsToPlay := 2
 
-	// Sort hand by strategy (play high-cost cards first if possible)
-	playableCards := ai.getPlayableCards(aiPlayer)
+	for cardsPlayed < maxCardsToPlay {
+		// Ask the engine again each time since the hand and mana changed
+		legal, _ := engine.LegalActions(game.ID, aiPlayerID)
 
-	for _, cardIndex := range playableCards {
-		if cardsPlayed >= maxCardsToPlay {
+		// Sort hand by strategy (play high-cost cards first if possible)
+		playableCards := ai.getPlayableCards(aiPlayer, legal)
+		if len(playableCards) == 0 {
 			break
 		}
 
-		if err := engine.PlayCard(game.ID, aiPlayerID, cardIndex); err == nil {
-			cardsPlayed++
-			time.Sleep(AIActionDelay)
-			// Recalculate playable cards since hand changed
-			aiPlayer = ai.getAIPlayer(game, aiPlayerID)
-			playableCards = ai.getPlayableCards(aiPlayer)
+		if _, err := engine.Apply(game.ID, aiPlayerID, playableCards[0]); err != nil {
+			break
 		}
+		cardsPlayed++
+		time.Sleep(AIActionDelay)
+
+		// Snapshots do not change; fetch the board as it is after the play
+		game, _ = engine.GetGameState(game.ID)
+		aiPlayer = ai.getAIPlayer(game, aiPlayerID)
 	}
 
 	// Decide whether to enter battle phase
 	if len(aiPlayer.Field) > 0 && ai.shouldEnterBattle(game, aiPlayerID) {
-		engine.ChangePhase(game.ID, aiPlayerID, battle.PhaseBattle)
+		engine.Apply(game.ID, aiPlayerID, battle.Action{Type: battle.ActionChangePhase, Phase: battle.PhaseBattle})
 	} else {
-		engine.EndTurn(game.ID, aiPlayerID)
+		engine.Apply(game.ID, aiPlayerID, battle.Action{Type: battle.ActionEndTurn})
 	}
 }
 
 // handleBattlePhase handles AI decisions during battle phase
 func (ai *AIPlayer) handleBattlePhase(game *battle.GameState, engine *battle.BattleEngine, aiPlayerID string) {
 	aiPlayer := ai.getAIPlayer(game, aiPlayerID)
-	opponent := ai.getOpponent(game, aiPlayerID)
+
+	// Remember the attackers up front; the field shifts as cards are destroyed
+	var attackers []string
+	for _, card := range aiPlayer.Field {
+		attackers = append(attackers, card.InstanceID)
+	}
 
 	// Attack with all creatures
-	for i := 0; i < len(aiPlayer.Field); i++ {
-		attacker := aiPlayer.Field[i]
-
-		if len(opponent.Field) > 0 {
-			// Choose target based on strategy
-			targetIndex := ai.chooseBattleTarget(attacker, opponent.Field)
-			engine.Attack(game.ID, aiPlayerID, i, targetIndex)
-		} else {
-			// Direct attack
-			engine.Attack(game.ID, aiPlayerID, i, -1)
-		}
+	for _, attackerID := range attackers {
+		legal, _ := engine.LegalActions(game.ID, aiPlayerID)
 
-		time.Sleep(AIActionDelay)
+		// Choose target based on strategy
+		if attack, ok := ai.chooseAttack(game, aiPlayerID, legal, attackerID); ok {
+			engine.Apply(game.ID, aiPlayerID, attack)
+			time.Sleep(AIActionDelay)
+		}
 
 		// Check if game ended
 		game, _ = engine.GetGameState(game.ID)
 		if game.GameOver {
 			return
 		}
-
-		// Refresh player states
-		aiPlayer = ai.getAIPlayer(game, aiPlayerID)
-		opponent = ai.getOpponent(game, aiPlayerID)
 	}
 
 	// End turn after attacks
-	engine.EndTurn(game.ID, aiPlayerID)
+	engine.Apply(game.ID, aiPlayerID, battle.Action{Type: battle.ActionEndTurn})
 }
 
-// getPlayableCards returns indices of cards that can be played
-func (ai *AIPlayer) getPlayableCards(player *battle.Player) []int {
-	var playable []int
+// getPlayableCards returns the legal play card actions, most expensive card first
+func (ai *AIPlayer) getPlayableCards(player *battle.Player, legal []battle.Action) []battle.Action {
+	var playable []battle.Action
 
-	for i, card := range player.Hand {
-		if card.Cost <= player.Mana {
-			playable = append(playable, i)
+	for _, action := range legal {
+		if action.Type == battle.ActionPlayCard {
+			playable = append(playable, action)
 		}
 	}
 
+	cost := make(map[string]int)
+	for _, card := range player.Hand {
+		cost[card.InstanceID] = card.Cost
+	}
+
 	// Sort by cost (higher cost first for better plays)
 	for i := 0; i < len(playable)-1; i++ {
 		for j := i + 1; j < len(playable); j++ {
-			if player.Hand[playable[i]].Cost < player.Hand[playable[j]].Cost {
+			if cost[playable[i].CardID] < cost[playable[j].CardID] {
 				playable[i], playable[j] = playable[j], playable[i]
 			}
 		}
@@ -126,6 +166,40 @@ func (ai *AIPlayer) getPlayableCards(player *battle.Player) []int {
 	return playable
 }
 
+// chooseAttack picks one of the legal attacks for the given attacker, if any
+func (ai *AIPlayer) chooseAttack(game *battle.GameState, aiPlayerID string, legal []battle.Action, attackerID string) (battle.Action, bool) {
+	aiPlayer := ai.getAIPlayer(game, aiPlayerID)
+	opponent := ai.getOpponent(game, aiPlayerID)
+
+	var attacks []battle.Action
+	var targets []battle.Card
+
+	for _, action := range legal {
+		if action.Type != battle.ActionAttack || action.AttackerID != attackerID {
+			continue
+		}
+
+		// Always take a direct attack when one is available
+		if action.TargetID == "" {
+			return action, true
+		}
+
+		target, ok := ai.findCard(opponent.Field, action.TargetID)
+		if !ok {
+			continue
+		}
+		attacks = append(attacks, action)
+		targets = append(targets, target)
+	}
+
+	attacker, ok := ai.findCard(aiPlayer.Field, attackerID)
+	if len(attacks) == 0 || !ok {
+		return battle.Action{}, false
+	}
+
+	return attacks[ai.chooseBattleTarget(attacker, targets)], true
+}
+
 // shouldEnterBattle decides if AI should enter battle phase
 func (ai *AIPlayer) shouldEnterBattle(game *battle.GameState, aiPlayerID string) bool {
 	aiPlayer := ai.getAIPlayer(game, aiPlayerID)
@@ -184,6 +258,15 @@ func (ai *AIPlayer) getAIPlayer(game *battle.GameState, aiPlayerID string) *batt
 	return game.Player2
 }
 
+func (ai *AIPlayer) findCard(cards []battle.Card, instanceID string) (battle.Card, bool) {
+	for _, card := range cards {
+		if card.InstanceID == instanceID {
+			return card, true
+		}
+	}
+	return battle.Card{}, false
+}
+
 func (ai *AIPlayer) getOpponent(game *battle.GameState, aiPlayerID string) *battle.Player {
 	if game.Player1.ID == aiPlayerID {
 		return game.Player2
@@ -14,6 +14,7 @@ type Game struct {
 	ai          *AIPlayer
 	deckBuilder *DeckBuilder
 	gameState   *battle.GameState
+	rules       battle.RuleSet
 }
 
 // NewGame creates a new game instance
@@ -24,19 +25,21 @@ func NewGame() *Game {
 		input:       NewInputHandler(),
 		ai:          NewAIPlayer("normal"),
 		deckBuilder: NewDeckBuilder(),
+		rules:       battle.StandardRules(),
 	}
 }
 
 // Run starts and runs the game
 func (g *Game) Run() error {
 	// Show welcome screen
+	g.display.SetRules(g.rules)
 	g.display.ShowWelcome()
 	
 	// Get player deck choice
 	playerDeck, aiDeck := g.selectDecks()
 	
 	// Create the match
-	gameState, err := g.engine.CreateMatch("Player", "AI", playerDeck, aiDeck)
+	gameState, err := g.engine.CreateMatch("Player", "AI", playerDeck, aiDeck, g.rules)
 	if err != nil {
 		return fmt.Errorf("failed to create match: %v", err)
 	}
@@ -75,22 +78,68 @@ func (g *Game) selectDecks() ([]battle.Card, []battle.Card) {
 
 // runGameLoop runs the main game loop
 func (g *Game) runGameLoop() {
-	for !g.gameState.GameOver {
+	for {
 		// Update game state
 		g.gameState, _ = g.engine.GetGameState(g.gameState.ID)
+		if g.gameState.GameOver {
+			break
+		}
+		
+		// Nobody else times the match, so a clock that ran out while
+		// waiting on input is expired here
+		if g.expireClock() {
+			continue
+		}
 		
 		// Display current state
 		g.display.ShowGameState(g.gameState)
 		
-		// Check whose turn it is
-		if g.gameState.CurrentTurn == "AI" {
+		// While the stack is open the player with priority acts,
+		// otherwise whoever's turn it is
+		switch {
+		case g.gameState.Phase == battle.PhaseMulligan:
+			g.handleMulligan()
+		case g.gameState.Priority == "AI":
+			g.ai.Respond(g.gameState, g.engine, "AI")
+		case g.gameState.Priority == "Player":
+			g.handlePlayerResponse()
+		case g.gameState.CurrentTurn == "AI":
 			g.handleAITurn()
-		} else {
+		default:
 			g.handlePlayerTurn()
 		}
 	}
 }
 
+// expireClock records a timeout if the running clock has run out,
+// reporting whether it did
+func (g *Game) expireClock() bool {
+	deadline, running := g.engine.ClockDeadline(g.gameState.ID)
+	if !running || time.Now().Before(deadline) {
+		return false
+	}
+	
+	result, err := g.engine.ExpireClock(g.gameState.ID)
+	if err != nil {
+		return false
+	}
+	g.display.ShowMessage(result.Message, ColorRed)
+	g.input.WaitForEnter("Press Enter to continue...")
+	return true
+}
+
+// handleMulligan lets whichever side has not decided on its opening hand do so
+func (g *Game) handleMulligan() {
+	if !g.gameState.Player2.MulliganDone {
+		g.ai.Mulligan(g.gameState, g.engine, "AI")
+		return
+	}
+	
+	g.display.ShowMulliganCommands()
+	command, args := g.input.GetCommand()
+	g.processCommand(command, args)
+}
+
 // handleAITurn handles the AI's turn
 func (g *Game) handleAITurn() {
 	g.display.ShowCommands(g.gameState.Phase, false)
@@ -102,78 +151,138 @@ func (g *Game) handlePlayerTurn() {
 	// Auto-draw at start of turn
 	if g.gameState.Phase == battle.PhaseDrawn {
 		g.display.ShowMessage("Drawing card...", ColorGreen)
-		g.engine.DrawCard(g.gameState.ID, "Player")
+		g.engine.Apply(g.gameState.ID, "Player", battle.Action{Type: battle.ActionDraw})
 		time.Sleep(500 * time.Millisecond)
 		return
 	}
 	
 	// Show available commands
 	g.display.ShowCommands(g.gameState.Phase, true)
+	legal, _ := g.engine.LegalActions(g.gameState.ID, "Player")
+	g.display.ShowLegalActions(legal)
 	
 	// Get and process player command
 	command, args := g.input.GetCommand()
 	g.processCommand(command, args)
 }
 
+// handlePlayerResponse lets the player answer a move on the stack. The
+// window opens even when the player has no response, so it is passed for them.
+func (g *Game) handlePlayerResponse() {
+	legal, _ := g.engine.LegalActions(g.gameState.ID, "Player")
+	if len(legal) == 1 && legal[0].Type == battle.ActionPass {
+		g.engine.Apply(g.gameState.ID, "Player", legal[0])
+		return
+	}
+	
+	g.display.ShowResponseCommands()
+	g.display.ShowLegalActions(legal)
+	
+	command, args := g.input.GetCommand()
+	g.processCommand(command, args)
+}
+
 // processCommand processes player commands
 func (g *Game) processCommand(command string, args []string) {
+	var action battle.Action
 	var err error
 	
 	switch command {
 	case "play":
-		err = g.handlePlayCommand(args)
+		action, err = g.parsePlayCommand(args)
 		
 	case "attack":
-		err = g.handleAttackCommand(args)
+		action, err = g.parseAttackCommand(args)
 		
 	case "battle":
-		err = g.engine.ChangePhase(g.gameState.ID, "Player", battle.PhaseBattle)
+		action = battle.Action{Type: battle.ActionChangePhase, Phase: battle.PhaseBattle}
 		
-	case "main":
-		err = g.engine.ChangePhase(g.gameState.ID, "Player", battle.PhaseMain)
+	case "main", "main2":
+		action = battle.Action{Type: battle.ActionChangePhase, Phase: battle.PhaseMain2}
 		
 	case "end":
-		err = g.engine.EndTurn(g.gameState.ID, "Player")
+		action = battle.Action{Type: battle.ActionEndTurn}
+		
+	case "respond":
+		action, err = g.parseRespondCommand(args)
+		
+	case "pass":
+		action = battle.Action{Type: battle.ActionPass}
+		
+	case "keep":
+		action = battle.Action{Type: battle.ActionMulligan}
+		
+	case "mulligan":
+		var cardIDs []string
+		cardIDs, err = g.input.ParseMulliganCards(args, g.gameState.Player1.Hand)
+		action = battle.Action{Type: battle.ActionMulligan, CardIDs: cardIDs}
 		
 	case "help":
 		g.display.ShowCommands(g.gameState.Phase, true)
 		g.input.WaitForEnter("Press Enter to continue...")
 		
-	case "quit":
-		g.gameState.GameOver = true
-		g.gameState.Winner = "AI"
+	case "quit", "concede":
+		action = battle.Action{Type: battle.ActionConcede}
 		
 	default:
 		g.display.ShowMessage("Unknown command. Type 'help' for available commands.", ColorRed)
 		g.input.WaitForEnter("Press Enter to continue...")
 	}
 	
+	if err == nil && action.Type != "" {
+		_, err = g.engine.Apply(g.gameState.ID, "Player", action)
+	}
+	
 	if err != nil {
 		g.display.ShowError(err)
 		g.input.WaitForEnter("")
 	}
 }
 
-// handlePlayCommand handles the play card command
-func (g *Game) handlePlayCommand(args []string) error {
+// parsePlayCommand builds a play card action from command arguments
+func (g *Game) parsePlayCommand(args []string) (battle.Action, error) {
+	if len(args) < 1 {
+		return battle.Action{}, fmt.Errorf("usage: play [card number]")
+	}
+	
+	cardID, err := g.input.ParseCardRef(args[0], g.gameState.Player1.Hand)
+	if err != nil {
+		return battle.Action{}, err
+	}
+	
+	// Equipment names the field card it attaches to
+	targetID := ""
+	if len(args) > 1 {
+		if targetID, err = g.input.ParseCardRef(args[1], g.gameState.Player1.Field); err != nil {
+			return battle.Action{}, err
+		}
+	}
+	
+	return battle.Action{Type: battle.ActionPlayCard, CardID: cardID, TargetID: targetID}, nil
+}
+
+// parseRespondCommand builds a response from an instant in hand or a set trap
+func (g *Game) parseRespondCommand(args []string) (battle.Action, error) {
 	if len(args) < 1 {
-		return fmt.Errorf("usage: play [card number]")
+		return battle.Action{}, fmt.Errorf("usage: respond [card]")
 	}
 	
-	cardIndex, err := g.input.ParseCardIndex(args[0])
+	player := g.gameState.Player1
+	cards := append(append([]battle.Card(nil), player.Hand...), player.Traps...)
+	cardID, err := g.input.ParseCardRef(args[0], cards)
 	if err != nil {
-		return err
+		return battle.Action{}, err
 	}
 	
-	return g.engine.PlayCard(g.gameState.ID, "Player", cardIndex)
+	return battle.Action{Type: battle.ActionRespond, CardID: cardID}, nil
 }
 
-// handleAttackCommand handles the attack command
-func (g *Game) handleAttackCommand(args []string) error {
-	attackerIndex, targetIndex, err := g.input.ParseAttackTargets(args)
+// parseAttackCommand builds an attack action from command arguments
+func (g *Game) parseAttackCommand(args []string) (battle.Action, error) {
+	attackerID, targetID, err := g.input.ParseAttackTargets(args, g.gameState.Player1.Field, g.gameState.Player2.Field)
 	if err != nil {
-		return err
+		return battle.Action{}, err
 	}
 	
-	return g.engine.Attack(g.gameState.ID, "Player", attackerIndex, targetIndex)
+	return battle.Action{Type: battle.ActionAttack, AttackerID: attackerID, TargetID: targetID}, nil
 }
\ No newline at end of file
@@ -2,6 +2,7 @@ package game
 
 import (
 	"bufio"
+	"cardgame/battle"
 	"fmt"
 	"os"
 	"strconv"
@@ -52,30 +53,71 @@ func (ih *InputHandler) GetCommand() (string, []string) {
 	return command, args
 }
 
-// ParseCardIndex parses a card index from string
-func (ih *InputHandler) ParseCardIndex(arg string) (int, error) {
-	index, err := strconv.Atoi(arg)
-	if err != nil {
-		return -1, fmt.Errorf("invalid card number: %s", arg)
-	}
-	return index, nil
+// ParseCardRef resolves a card number or instance ID to an instance ID
+func (ih *InputHandler) ParseCardRef(arg string, cards []battle.Card) (string, error) {
+	return ResolveCard(cards, arg)
+}
+
+// ParseMulliganCards resolves the card numbers or instance IDs of a mulligan
+// command to the instance IDs of the cards to shuffle back
+func (ih *InputHandler) ParseMulliganCards(args []string, hand []battle.Card) ([]string, error) {
+	return ResolveCards(hand, args)
 }
 
-// ParseAttackTargets parses attacker and target indices
-func (ih *InputHandler) ParseAttackTargets(args []string) (int, int, error) {
+// ParseAttackTargets resolves attacker and target references to instance IDs.
+// The target is empty for a direct attack.
+func (ih *InputHandler) ParseAttackTargets(args []string, field, opponentField []battle.Card) (string, string, error) {
 	if len(args) < 2 {
-		return -1, -1, fmt.Errorf("usage: attack [attacker index] [target index]")
+		return "", "", fmt.Errorf("usage: attack [attacker] [target]")
 	}
 	
-	attackerIndex, err := strconv.Atoi(args[0])
+	attackerID, err := ResolveCard(field, args[0])
 	if err != nil {
-		return -1, -1, fmt.Errorf("invalid attacker index: %s", args[0])
+		return "", "", err
 	}
 	
-	targetIndex, err := strconv.Atoi(args[1])
+	if args[1] == "-1" || strings.ToLower(args[1]) == "direct" {
+		return attackerID, "", nil
+	}
+	
+	targetID, err := ResolveCard(opponentField, args[1])
 	if err != nil {
-		return -1, -1, fmt.Errorf("invalid target index: %s", args[1])
+		return "", "", err
+	}
+	
+	return attackerID, targetID, nil
+}
+
+// ResolveCards resolves every non-empty argument with ResolveCard
+func ResolveCards(cards []battle.Card, args []string) ([]string, error) {
+	var cardIDs []string
+	for _, arg := range args {
+		if arg == "" {
+			continue
+		}
+		cardID, err := ResolveCard(cards, arg)
+		if err != nil {
+			return nil, err
+		}
+		cardIDs = append(cardIDs, cardID)
+	}
+	return cardIDs, nil
+}
+
+// ResolveCard turns a command argument into a card instance ID. The argument
+// is either the card's position as displayed or its instance ID.
+func ResolveCard(cards []battle.Card, arg string) (string, error) {
+	if index, err := strconv.Atoi(arg); err == nil {
+		if index < 0 || index >= len(cards) {
+			return "", fmt.Errorf("invalid card number: %s", arg)
+		}
+		return cards[index].InstanceID, nil
 	}
 	
-	return attackerIndex, targetIndex, nil
+	for _, card := range cards {
+		if card.InstanceID == arg {
+			return card.InstanceID, nil
+		}
+	}
+	return "", fmt.Errorf("no card %s", arg)
 }
\ No newline at end of file
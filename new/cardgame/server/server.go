@@ -3,6 +3,9 @@ package server
 import (
 	"cardgame/battle"
 	"cardgame/shared"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"log"
@@ -13,12 +16,45 @@ import (
 	"github.com/gorilla/websocket"
 )
 
+// DefaultMatchTTL is how long a match may sit idle before it is evicted
+const DefaultMatchTTL = 30 * time.Minute
+
+// Default time controls of online matches, so an idle opponent cannot stall
+// a match. The rule presets are untimed.
+const (
+	DefaultTurnTime     = 90 * time.Second
+	DefaultMaxTimeouts  = 3
+	DefaultResponseTime = 15 * time.Second
+)
+
+// TimedRules returns rules with the server's default time controls
+func TimedRules(rules battle.RuleSet) battle.RuleSet {
+	rules.TurnTime = DefaultTurnTime
+	rules.MaxTimeouts = DefaultMaxTimeouts
+	rules.ResponseTime = DefaultResponseTime
+	return rules
+}
+
+// evictionInterval is how often idle matches are looked for
+const evictionInterval = time.Minute
+
+// matchUpdateBuffer is how many state changes a match's watcher may fall
+// behind by before it has to catch up from the current state
+const matchUpdateBuffer = 256
+
 // GameServer manages all online games
 type GameServer struct {
 	port       string
+	engine     *battle.BattleEngine
+	httpServer *http.Server
+	ctx        context.Context
+	stop       context.CancelFunc
 	games      map[string]*OnlineGame
 	players    map[string]*Player
 	matchQueue []*Player
+	rules      battle.RuleSet
+	matchTTL   time.Duration
+	codec      *battle.Codec
 	upgrader   websocket.Upgrader
 	mu         sync.RWMutex
 }
@@ -31,31 +67,70 @@ type Player struct {
 	GameID     string
 	DeckChoice string
 	mu         sync.Mutex
+
+	// Binary players are sent game updates and send actions in the
+	// battle codec's binary encoding instead of JSON
+	Binary bool
 }
 
-// OnlineGame represents an online game session
+// OnlineGame represents an online game session. The engine runs the match;
+// the game's watcher goroutine relays its state changes to the players.
 type OnlineGame struct {
 	ID         string
 	Engine     *battle.BattleEngine
-	State      *battle.GameState
 	Player1    *Player
 	Player2    *Player
 	Spectators []*Player
-	mu         sync.RWMutex
+
+	// clockTimer belongs to the watcher. It fires when the running clock
+	// of a timed match runs out, including response windows and the
+	// mulligan.
+	clockTimer *time.Timer
 }
 
-// NewGameServer creates a new game server
-func NewGameServer(port string) *GameServer {
+// NewGameServer creates a new game server. It fails if the cards on offer
+// do not make a consistent catalog for the binary encoding.
+func NewGameServer(port string) (*GameServer, error) {
+	// Binary updates and replays refer to cards in the catalog of every
+	// deck on offer
+	deckBuilder := &DeckBuilder{}
+	codec, err := battle.NewCodec(append(deckBuilder.CreateEgyptianDeck(), deckBuilder.CreateGreekDeck()...))
+	if err != nil {
+		return nil, fmt.Errorf("invalid card catalog: %v", err)
+	}
+
+	ctx, stop := context.WithCancel(context.Background())
 	return &GameServer{
-		port:    port,
-		games:   make(map[string]*OnlineGame),
-		players: make(map[string]*Player),
+		port:       port,
+		engine:     battle.NewBattleEngineContext(ctx),
+		httpServer: &http.Server{Addr: ":" + port},
+		ctx:        ctx,
+		stop:       stop,
+		games:      make(map[string]*OnlineGame),
+		players:    make(map[string]*Player),
+		rules:      TimedRules(battle.StandardRules()),
+		matchTTL:   DefaultMatchTTL,
+		codec:      codec,
 		upgrader: websocket.Upgrader{
 			CheckOrigin: func(r *http.Request) bool {
 				return true // Allow all origins in development
 			},
 		},
-	}
+	}, nil
+}
+
+// SetRules sets the rules new matches are created with
+func (gs *GameServer) SetRules(rules battle.RuleSet) {
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+	gs.rules = rules
+}
+
+// SetMatchTTL sets how long a match may sit idle before it is evicted
+func (gs *GameServer) SetMatchTTL(ttl time.Duration) {
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+	gs.matchTTL = ttl
 }
 
 // Start starts the game server
@@ -63,11 +138,24 @@ func (gs *GameServer) Start() error {
 	// Set up routes
 	http.HandleFunc("/ws", gs.handleWebSocket)
 	http.HandleFunc("/status", gs.handleStatus)
+	http.HandleFunc("/matches", gs.handleMatches)
+	http.HandleFunc("/replay", gs.handleReplay)
 
-	// Start matchmaking goroutine
+	// Start matchmaking and eviction goroutines
 	go gs.runMatchmaking()
+	go gs.runEviction()
 
-	return http.ListenAndServe(":"+gs.port, nil)
+	return gs.httpServer.ListenAndServe()
+}
+
+// Shutdown stops accepting connections and matchmaking, then stops every
+// match, waiting until ctx is done at the latest
+func (gs *GameServer) Shutdown(ctx context.Context) error {
+	gs.stop()
+	if err := gs.httpServer.Shutdown(ctx); err != nil {
+		return err
+	}
+	return gs.engine.Shutdown(ctx)
 }
 
 // handleWebSocket handles new WebSocket connections
@@ -81,8 +169,9 @@ func (gs *GameServer) handleWebSocket(w http.ResponseWriter, r *http.Request) {
 	// Create new player
 	playerID := generateID()
 	player := &Player{
-		ID:   playerID,
-		Conn: conn,
+		ID:     playerID,
+		Conn:   conn,
+		Binary: r.URL.Query().Get("encoding") == "binary",
 	}
 
 	gs.mu.Lock()
@@ -98,6 +187,16 @@ func (gs *GameServer) handleWebSocket(w http.ResponseWriter, r *http.Request) {
 		},
 	})
 
+	// Binary players decode cards against the server's catalog
+	if player.Binary {
+		gs.sendToPlayer(player, shared.Message{
+			Type: shared.MsgCatalog,
+			Data: map[string]interface{}{
+				"cards": gs.codec.Catalog(),
+			},
+		})
+	}
+
 	// Handle player messages
 	go gs.handlePlayer(player)
 }
@@ -110,13 +209,28 @@ func (gs *GameServer) handlePlayer(player *Player) {
 	}()
 
 	for {
-		var msg shared.Message
-		err := player.Conn.ReadJSON(&msg)
+		messageType, data, err := player.Conn.ReadMessage()
 		if err != nil {
 			log.Printf("Read error for player %s: %v", player.ID, err)
 			return
 		}
 
+		// Binary frames carry encoded actions
+		if messageType == websocket.BinaryMessage {
+			action, err := battle.DecodeAction(data)
+			if err != nil {
+				gs.sendError(player, "invalid action")
+				continue
+			}
+			gs.handleAction(player, action)
+			continue
+		}
+
+		var msg shared.Message
+		if err := json.Unmarshal(data, &msg); err != nil {
+			log.Printf("Read error for player %s: %v", player.ID, err)
+			return
+		}
 		gs.processMessage(player, msg)
 	}
 }
@@ -130,16 +244,26 @@ func (gs *GameServer) processMessage(player *Player, msg shared.Message) {
 		gs.handleJoinQueue(player, msg)
 	case shared.MsgLeaveQueue:
 		gs.handleLeaveQueue(player)
+	case shared.MsgAction:
+		gs.handleActionMessage(player, msg)
 	case shared.MsgPlayCard:
-		gs.handlePlayCard(player, msg)
+		gs.handleLegacyPlayCard(player, msg)
 	case shared.MsgAttack:
-		gs.handleAttack(player, msg)
+		gs.handleLegacyAttack(player, msg)
 	case shared.MsgEndTurn:
-		gs.handleEndTurn(player)
+		gs.handleAction(player, battle.Action{Type: battle.ActionEndTurn})
 	case shared.MsgChangePhase:
-		gs.handleChangePhase(player, msg)
+		data := msg.Data.(map[string]interface{})
+		gs.handleAction(player, battle.Action{
+			Type:  battle.ActionChangePhase,
+			Phase: battle.GamePhase(data["phase"].(string)),
+		})
 	case shared.MsgDrawCard:
-		gs.handleDrawCard(player)
+		gs.handleAction(player, battle.Action{Type: battle.ActionDraw})
+	case shared.MsgMulligan:
+		gs.handleMulligan(player, msg)
+	case shared.MsgDesync:
+		gs.handleDesync(player, msg)
 	}
 }
 
@@ -203,27 +327,32 @@ func (gs *GameServer) handleLeaveQueue(player *Player) {
 // runMatchmaking runs the matchmaking loop
 func (gs *GameServer) runMatchmaking() {
 	for {
+		var player1, player2 *Player
 		gs.mu.Lock()
 		if len(gs.matchQueue) >= 2 {
 			// Get first two players
-			player1 := gs.matchQueue[0]
-			player2 := gs.matchQueue[1]
+			player1 = gs.matchQueue[0]
+			player2 = gs.matchQueue[1]
 			gs.matchQueue = gs.matchQueue[2:]
+		}
+		gs.mu.Unlock()
 
-			// Create game
+		// Create game
+		if player1 != nil {
 			gs.createGame(player1, player2)
 		}
-		gs.mu.Unlock()
 
 		// Small delay to prevent busy waiting
-		<-time.After(100 * time.Millisecond)
+		select {
+		case <-gs.ctx.Done():
+			return
+		case <-time.After(100 * time.Millisecond):
+		}
 	}
 }
 
 // createGame creates a new game between two players
 func (gs *GameServer) createGame(player1, player2 *Player) {
-	gameID := generateID()
-
 	// Create decks based on player choices
 	deckBuilder := &DeckBuilder{}
 	var deck1, deck2 []battle.Card
@@ -240,19 +369,29 @@ func (gs *GameServer) createGame(player1, player2 *Player) {
 		deck2 = deckBuilder.CreateGreekDeck()
 	}
 
-	// Create battle engine and game
-	engine := battle.NewBattleEngine()
-	gameState, err := engine.CreateMatch(player1.ID, player2.ID, deck1, deck2)
+	// Create the match on the shared engine
+	gs.mu.RLock()
+	rules := gs.rules
+	gs.mu.RUnlock()
+
+	engine := gs.engine
+	gameState, err := engine.CreateMatch(player1.ID, player2.ID, deck1, deck2, rules)
 	if err != nil {
 		log.Printf("Error creating game: %v", err)
 		return
 	}
+	gameID := gameState.ID
+
+	updates, err := engine.Subscribe(gameID, matchUpdateBuffer)
+	if err != nil {
+		log.Printf("Error watching game %s: %v", gameID, err)
+		return
+	}
 
 	// Create online game
 	onlineGame := &OnlineGame{
 		ID:      gameID,
 		Engine:  engine,
-		State:   gameState,
 		Player1: player1,
 		Player2: player2,
 	}
@@ -264,158 +403,276 @@ func (gs *GameServer) createGame(player1, player2 *Player) {
 	player2.GameID = gameID
 	gs.mu.Unlock()
 
-	// Notify players
-	gs.sendToPlayer(player1, shared.Message{
+	// The watcher tells the players the game has started
+	go gs.watchGame(onlineGame, updates)
+
+	log.Printf("Game %s started: %s vs %s (seed %d)", gameID, player1.Name, player2.Name, gameState.Seed)
+}
+
+// startGame sends each player the opening state of their game
+func (gs *GameServer) startGame(game *OnlineGame, change battle.StateChange) {
+	state := change.State
+	gs.sendToPlayer(game.Player1, shared.Message{
 		Type: shared.MsgGameStart,
 		Data: map[string]interface{}{
-			"gameID":       gameID,
+			"gameID":       game.ID,
 			"playerNum":    1,
-			"opponentName": player2.Name,
-			"gameState":    gameState,
+			"opponentName": game.Player2.Name,
+			"gameState":    state.ViewFor(game.Player1.ID),
+			"legalActions": change.Legal[game.Player1.ID],
 		},
 	})
 
-	gs.sendToPlayer(player2, shared.Message{
+	gs.sendToPlayer(game.Player2, shared.Message{
 		Type: shared.MsgGameStart,
 		Data: map[string]interface{}{
-			"gameID":       gameID,
+			"gameID":       game.ID,
 			"playerNum":    2,
-			"opponentName": player1.Name,
-			"gameState":    gameState,
+			"opponentName": game.Player1.Name,
+			"gameState":    state.ViewFor(game.Player2.ID),
+			"legalActions": change.Legal[game.Player2.ID],
 		},
 	})
-
-	log.Printf("Game %s started: %s vs %s", gameID, player1.Name, player2.Name)
 }
 
 // Game action handlers
 
-func (gs *GameServer) handlePlayCard(player *Player, msg shared.Message) {
-	game := gs.getPlayerGame(player)
-	if game == nil {
+// handleActionMessage handles a generic action message
+func (gs *GameServer) handleActionMessage(player *Player, msg shared.Message) {
+	action, err := shared.ConvertToAction(msg.Data)
+	if err != nil {
+		gs.sendError(player, "invalid action")
 		return
 	}
 
-	data := msg.Data.(map[string]interface{})
-	cardIndex := int(data["cardIndex"].(float64))
+	gs.handleAction(player, action)
+}
 
-	game.mu.Lock()
-	err := game.Engine.PlayCard(game.State.ID, player.ID, cardIndex)
-	if err != nil {
-		game.mu.Unlock()
-		gs.sendError(player, err.Error())
+// handleLegacyPlayCard handles the index based play card message by
+// resolving the index against the player's current hand
+func (gs *GameServer) handleLegacyPlayCard(player *Player, msg shared.Message) {
+	data := msg.Data.(map[string]interface{})
+	view := gs.playerView(player)
+	if view == nil {
 		return
 	}
 
-	// Get updated state
-	game.State, _ = game.Engine.GetGameState(game.State.ID)
-	game.mu.Unlock()
+	cardID, ok := cardAt(view.Me().Hand, data["cardIndex"])
+	if !ok {
+		gs.sendError(player, "invalid card index")
+		return
+	}
 
-	// Broadcast to both players
-	gs.broadcastGameState(game)
+	gs.handleAction(player, battle.Action{Type: battle.ActionPlayCard, CardID: cardID})
 }
 
-func (gs *GameServer) handleAttack(player *Player, msg shared.Message) {
-	game := gs.getPlayerGame(player)
-	if game == nil {
+// handleLegacyAttack handles the index based attack message by resolving
+// the indices against the current fields. A target index of -1 is a direct attack.
+func (gs *GameServer) handleLegacyAttack(player *Player, msg shared.Message) {
+	data := msg.Data.(map[string]interface{})
+	view := gs.playerView(player)
+	if view == nil {
 		return
 	}
 
-	data := msg.Data.(map[string]interface{})
-	attackerIndex := int(data["attackerIndex"].(float64))
-	targetIndex := int(data["targetIndex"].(float64))
-
-	game.mu.Lock()
-	err := game.Engine.Attack(game.State.ID, player.ID, attackerIndex, targetIndex)
-	if err != nil {
-		game.mu.Unlock()
-		gs.sendError(player, err.Error())
+	attackerID, ok := cardAt(view.Me().Field, data["attackerIndex"])
+	if !ok {
+		gs.sendError(player, "invalid attacker index")
 		return
 	}
 
-	// Get updated state
-	game.State, _ = game.Engine.GetGameState(game.State.ID)
-	game.mu.Unlock()
+	targetID := ""
+	if index, _ := data["targetIndex"].(float64); index != -1 {
+		if targetID, ok = cardAt(view.Opponent().Field, data["targetIndex"]); !ok {
+			gs.sendError(player, "invalid target index")
+			return
+		}
+	}
+
+	gs.handleAction(player, battle.Action{Type: battle.ActionAttack, AttackerID: attackerID, TargetID: targetID})
+}
+
+// handleMulligan handles a mulligan message listing the instance IDs of the
+// cards to shuffle back under "cardIDs", none to keep the hand. The player is
+// told how many cards they redrew and whether the opponent is still deciding.
+func (gs *GameServer) handleMulligan(player *Player, msg shared.Message) {
+	var cardIDs []string
+	if data, ok := msg.Data.(map[string]interface{}); ok {
+		ids, _ := data["cardIDs"].([]interface{})
+		for _, id := range ids {
+			cardID, ok := id.(string)
+			if !ok {
+				gs.sendError(player, "invalid card ID")
+				return
+			}
+			cardIDs = append(cardIDs, cardID)
+		}
+	}
 
-	// Broadcast to both players
-	gs.broadcastGameState(game)
+	if !gs.handleAction(player, battle.Action{Type: battle.ActionMulligan, CardIDs: cardIDs}) {
+		return
+	}
 
-	// Check for game over
-	if game.State.GameOver {
-		gs.handleGameOver(game)
+	view := gs.playerView(player)
+	if view == nil {
+		return
 	}
+	gs.sendToPlayer(player, shared.Message{
+		Type: shared.MsgMulliganDone,
+		Data: map[string]interface{}{
+			"redrawn": len(cardIDs),
+			"waiting": view.Phase == battle.PhaseMulligan,
+		},
+	})
 }
 
-func (gs *GameServer) handleEndTurn(player *Player) {
+// handleDesync handles a client reporting that the state it decoded does
+// not hash to what the server sent. The report is logged and the player is
+// sent their current view to start over from. Nothing is recovered beyond
+// that: if the client decodes the resent view wrongly too, it stays out of
+// sync, and the client only reports each seq once.
+func (gs *GameServer) handleDesync(player *Player, msg shared.Message) {
+	data, _ := msg.Data.(map[string]interface{})
+	seq, _ := data["seq"].(float64)
+	log.Printf("Player %s reported a desync in game %s at seq %d (expected %v, got %v)",
+		player.ID, player.GameID, int(seq), data["expected"], data["actual"])
+
 	game := gs.getPlayerGame(player)
 	if game == nil {
 		return
 	}
-
-	game.mu.Lock()
-	err := game.Engine.EndTurn(game.State.ID, player.ID)
+	view, legal, err := game.Engine.GetGameViewWithActions(game.ID, player.ID)
 	if err != nil {
-		game.mu.Unlock()
-		gs.sendError(player, err.Error())
 		return
 	}
-
-	// Get updated state
-	game.State, _ = game.Engine.GetGameState(game.State.ID)
-	game.mu.Unlock()
-
-	// Broadcast to both players
-	gs.broadcastGameState(game)
+	gs.sendUpdate(player, view, legal)
 }
 
-func (gs *GameServer) handleChangePhase(player *Player, msg shared.Message) {
+// handleAction applies an action to the player's game, reporting whether
+// the engine accepted it. The game's watcher broadcasts the result.
+func (gs *GameServer) handleAction(player *Player, action battle.Action) bool {
 	game := gs.getPlayerGame(player)
 	if game == nil {
-		return
+		return false
 	}
 
-	data := msg.Data.(map[string]interface{})
-	phase := battle.GamePhase(data["phase"].(string))
-
-	game.mu.Lock()
-	err := game.Engine.ChangePhase(game.State.ID, player.ID, phase)
-	if err != nil {
-		game.mu.Unlock()
+	if _, err := game.Engine.Apply(game.ID, player.ID, action); err != nil {
 		gs.sendError(player, err.Error())
-		return
+		return false
 	}
+	return true
+}
+
+// watchGame relays a match's state changes to everyone in it until the
+// match ends or is closed. It is the only goroutine reacting to the match,
+// so broadcasts, timers and the game over happen in the order the engine
+// made the changes.
+func (gs *GameServer) watchGame(game *OnlineGame, updates *battle.Subscription) {
+	defer func() {
+		updates.Unsubscribe()
+		stopTimer(&game.clockTimer)
+	}()
+
+	started := false
+	for {
+		select {
+		case change, ok := <-updates.Updates():
+			if !ok {
+				// A watcher that fell behind catches up from the current
+				// state; once the match is gone there is nothing to watch
+				var err error
+				if updates, err = game.Engine.Subscribe(game.ID, matchUpdateBuffer); err != nil {
+					return
+				}
+				continue
+			}
+
+			state := change.State
+			if !started {
+				gs.startGame(game, change)
+				started = true
+			} else {
+				gs.broadcastGameState(game, change)
+			}
+
+			if state.GameOver {
+				gs.handleGameOver(game, state)
+				return
+			}
+			gs.armClockTimer(game)
+		case <-timerC(game.clockTimer):
+			game.clockTimer = nil
+			gs.expireClock(game)
+		}
+	}
+}
 
-	// Get updated state
-	game.State, _ = game.Engine.GetGameState(game.State.ID)
-	game.mu.Unlock()
+// timerC returns the channel of a timer, or nil for no timer so that a
+// select never picks it
+func timerC(timer *time.Timer) <-chan time.Time {
+	if timer == nil {
+		return nil
+	}
+	return timer.C
+}
 
-	// Broadcast to both players
-	gs.broadcastGameState(game)
+// stopTimer cancels a timer, if any
+func stopTimer(timer **time.Timer) {
+	if *timer != nil {
+		(*timer).Stop()
+		*timer = nil
+	}
 }
 
-func (gs *GameServer) handleDrawCard(player *Player) {
-	game := gs.getPlayerGame(player)
-	if game == nil {
+// armClockTimer schedules a timeout for when the running clock runs out.
+// Any earlier timer is cancelled since the clock restarts with every action.
+func (gs *GameServer) armClockTimer(game *OnlineGame) {
+	stopTimer(&game.clockTimer)
+
+	deadline, running := game.Engine.ClockDeadline(game.ID)
+	if !running {
 		return
 	}
+	game.clockTimer = time.NewTimer(time.Until(deadline))
+}
 
-	game.mu.Lock()
-	err := game.Engine.DrawCard(game.State.ID, player.ID)
+// expireClock records a timeout for the player whose clock ran out. The
+// engine passes for them, keeps their opening hand, ends their turn or,
+// once they are out of chances, ends the match.
+func (gs *GameServer) expireClock(game *OnlineGame) {
+	result, err := game.Engine.ExpireClock(game.ID)
 	if err != nil {
-		game.mu.Unlock()
-		gs.sendError(player, err.Error())
+		log.Printf("Error expiring clock in game %s: %v", game.ID, err)
 		return
 	}
+	log.Printf("Game %s: %s", game.ID, result.Message)
+}
+
+// Helper functions
 
-	// Get updated state
-	game.State, _ = game.Engine.GetGameState(game.State.ID)
-	game.mu.Unlock()
+// playerView returns the current view of the player's game, or nil if the
+// player is not in a game
+func (gs *GameServer) playerView(player *Player) *battle.GameView {
+	game := gs.getPlayerGame(player)
+	if game == nil {
+		return nil
+	}
 
-	// Broadcast to both players
-	gs.broadcastGameState(game)
+	view, err := game.Engine.GetGameView(game.ID, player.ID)
+	if err != nil {
+		return nil
+	}
+	return view
 }
 
-// Helper functions
+// cardAt returns the instance ID of the card at a JSON encoded index
+func cardAt(cards []battle.Card, index interface{}) (string, bool) {
+	i, ok := index.(float64)
+	if !ok || int(i) < 0 || int(i) >= len(cards) {
+		return "", false
+	}
+	return cards[int(i)].InstanceID, true
+}
 
 func (gs *GameServer) getPlayerGame(player *Player) *OnlineGame {
 	gs.mu.RLock()
@@ -428,36 +685,76 @@ func (gs *GameServer) getPlayerGame(player *Player) *OnlineGame {
 	return gs.games[player.GameID]
 }
 
-func (gs *GameServer) broadcastGameState(game *OnlineGame) {
-	game.mu.RLock()
-	state := game.State
-	game.mu.RUnlock()
+// broadcastGameState sends every participant the view of the game they are
+// allowed to see, with the moves open to them in that same state
+func (gs *GameServer) broadcastGameState(game *OnlineGame, change battle.StateChange) {
+	state := change.State
+	player1View := state.ViewFor(game.Player1.ID)
+	player2View := state.ViewFor(game.Player2.ID)
+	spectatorView := state.SpectatorView()
+	player1Legal := change.Legal[game.Player1.ID]
+	player2Legal := change.Legal[game.Player2.ID]
+	spectators := append([]*Player(nil), game.Spectators...)
+
+	gs.sendUpdate(game.Player1, player1View, player1Legal)
+	gs.sendUpdate(game.Player2, player2View, player2Legal)
+
+	for _, spectator := range spectators {
+		gs.sendUpdate(spectator, spectatorView, nil)
+	}
+}
 
-	msg := shared.Message{
+// sendUpdate sends a player a view of their game and the moves open to
+// them, encoded the way the player asked for
+func (gs *GameServer) sendUpdate(player *Player, view *battle.GameView, legal []battle.Action) {
+	if !player.Binary {
+		gs.sendToPlayer(player, gameUpdateMessage(view, legal))
+		return
+	}
+
+	player.mu.Lock()
+	defer player.mu.Unlock()
+
+	if err := player.Conn.WriteMessage(websocket.BinaryMessage, gs.codec.EncodeUpdate(view, legal)); err != nil {
+		log.Printf("Error sending to player %s: %v", player.ID, err)
+	}
+}
+
+// gameUpdateMessage carries a view with its version and hash, so the
+// receiver can check it decoded the same state. Only the view is hashed:
+// a hash of the whole game would let a player test guesses at the cards
+// hidden from them.
+func gameUpdateMessage(view *battle.GameView, legal []battle.Action) shared.Message {
+	return shared.Message{
 		Type: shared.MsgGameUpdate,
 		Data: map[string]interface{}{
-			"gameState": state,
+			"gameState":    view,
+			"legalActions": legal,
+			"seq":          view.Version,
+			"viewHash":     view.Hash(),
 		},
 	}
-
-	gs.sendToPlayer(game.Player1, msg)
-	gs.sendToPlayer(game.Player2, msg)
 }
 
-func (gs *GameServer) handleGameOver(game *OnlineGame) {
+func (gs *GameServer) handleGameOver(game *OnlineGame, state *battle.GameState) {
 	// Determine winner name
 	winnerName := ""
-	if game.State.Winner == game.Player1.ID {
+	switch state.Winner {
+	case game.Player1.ID:
 		winnerName = game.Player1.Name
-	} else {
+	case game.Player2.ID:
 		winnerName = game.Player2.Name
 	}
 
+	result := state.Result
 	msg := shared.Message{
 		Type: shared.MsgGameOver,
 		Data: map[string]interface{}{
-			"winner":     game.State.Winner,
+			"winner":     state.Winner,
 			"winnerName": winnerName,
+			"draw":       result.Draw,
+			"reason":     result.Reason,
+			"result":     result,
 		},
 	}
 
@@ -470,6 +767,54 @@ func (gs *GameServer) handleGameOver(game *OnlineGame) {
 	game.Player1.GameID = ""
 	game.Player2.GameID = ""
 	gs.mu.Unlock()
+
+	if _, err := gs.engine.CloseMatch(game.ID); err != nil {
+		log.Printf("Error closing game %s: %v", game.ID, err)
+	}
+}
+
+// runEviction regularly drops matches that have sat idle longer than the
+// match TTL, telling anyone still seated in one that it was closed
+func (gs *GameServer) runEviction() {
+	ticker := time.NewTicker(evictionInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-gs.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		gs.mu.RLock()
+		ttl := gs.matchTTL
+		gs.mu.RUnlock()
+
+		for _, gameID := range gs.engine.EvictIdle(ttl) {
+			gs.mu.Lock()
+			game := gs.games[gameID]
+			delete(gs.games, gameID)
+			if game != nil {
+				game.Player1.GameID = ""
+				game.Player2.GameID = ""
+			}
+			gs.mu.Unlock()
+
+			log.Printf("Game %s evicted after %v idle", gameID, ttl)
+			if game == nil {
+				continue
+			}
+
+			msg := shared.Message{
+				Type: shared.MsgError,
+				Data: map[string]interface{}{
+					"error": "match closed after inactivity",
+				},
+			}
+			gs.sendToPlayer(game.Player1, msg)
+			gs.sendToPlayer(game.Player2, msg)
+		}
+	}
 }
 
 func (gs *GameServer) sendToPlayer(player *Player, msg shared.Message) {
@@ -492,7 +837,6 @@ func (gs *GameServer) sendError(player *Player, errMsg string) {
 
 func (gs *GameServer) disconnectPlayer(player *Player) {
 	gs.mu.Lock()
-	defer gs.mu.Unlock()
 
 	// Remove from players map
 	delete(gs.players, player.ID)
@@ -506,25 +850,32 @@ func (gs *GameServer) disconnectPlayer(player *Player) {
 	}
 
 	// Handle game disconnection
+	var game *OnlineGame
 	if player.GameID != "" {
-		if game, exists := gs.games[player.GameID]; exists {
-			// Notify opponent
-			var opponent *Player
-			if game.Player1.ID == player.ID {
-				opponent = game.Player2
-			} else {
-				opponent = game.Player1
-			}
+		game = gs.games[player.GameID]
+	}
+	gs.mu.Unlock()
 
-			gs.sendToPlayer(opponent, shared.Message{
-				Type: shared.MsgOpponentDisconnected,
-				Data: map[string]interface{}{
-					"message": "Your opponent has disconnected",
-				},
-			})
+	if game != nil {
+		// Notify opponent
+		opponent := game.Player1
+		if game.Player1.ID == player.ID {
+			opponent = game.Player2
+		}
 
-			// Clean up game
-			delete(gs.games, player.GameID)
+		gs.sendToPlayer(opponent, shared.Message{
+			Type: shared.MsgOpponentDisconnected,
+			Data: map[string]interface{}{
+				"message": "Your opponent has disconnected",
+			},
+		})
+
+		// The player who left forfeits the match; the watcher ends it
+		_, err := game.Engine.Forfeit(game.ID, player.ID, battle.ReasonDisconnect)
+		if err != nil {
+			gs.mu.Lock()
+			delete(gs.games, game.ID)
+			gs.mu.Unlock()
 		}
 	}
 
@@ -545,7 +896,36 @@ func (gs *GameServer) handleStatus(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(status)
 }
 
-// generateID generates a unique ID
+// handleMatches lists the matches the server is running and the recently
+// closed ones
+func (gs *GameServer) handleMatches(w http.ResponseWriter, r *http.Request) {
+	matches := map[string]interface{}{
+		"active":   gs.engine.ListMatches(),
+		"archived": gs.engine.ListArchived(),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(matches)
+}
+
+// handleReplay serves a closed match as a binary replay file, encoded with
+// the server's codec
+func (gs *GameServer) handleReplay(w http.ResponseWriter, r *http.Request) {
+	gameID := r.URL.Query().Get("id")
+	record, err := gs.engine.ArchivedMatch(gameID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", gameID+".replay"))
+	w.Write(gs.codec.EncodeRecord(record))
+}
+
+// generateID generates a random unique ID
 func generateID() string {
-	return fmt.Sprintf("%d", time.Now().UnixNano())
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
 }
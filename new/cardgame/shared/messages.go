@@ -1,6 +1,9 @@
 package shared
 
-import "cardgame/battle"
+import (
+	"cardgame/battle"
+	"encoding/json"
+)
 
 // Message types
 const (
@@ -13,6 +16,9 @@ const (
 	MsgEndTurn      = "endTurn"
 	MsgChangePhase  = "changePhase"
 	MsgDrawCard     = "drawCard"
+	MsgAction       = "action"
+	MsgMulligan     = "mulligan"
+	MsgDesync       = "desync"
 	
 	// Server to Client
 	MsgWelcome              = "welcome"
@@ -24,6 +30,8 @@ const (
 	MsgGameOver             = "gameOver"
 	MsgError                = "error"
 	MsgOpponentDisconnected = "opponentDisconnected"
+	MsgMulliganDone         = "mulliganDone"
+	MsgCatalog              = "catalog"
 )
 
 // Message represents a network message
@@ -32,72 +40,41 @@ type Message struct {
 	Data interface{} `json:"data"`
 }
 
-// ConvertToGameState converts a map to GameState
-func ConvertToGameState(data map[string]interface{}) *battle.GameState {
-	// This is a simplified conversion - in production you'd want proper error handling
-	state := &battle.GameState{
-		ID:          data["id"].(string),
-		CurrentTurn: data["current_turn"].(string),
-		TurnCount:   int(data["turn_count"].(float64)),
-		Phase:       battle.GamePhase(data["phase"].(string)),
-		GameOver:    data["game_over"].(bool),
-		Winner:      data["winner"].(string),
-		LastAction:  data["last_action"].(string),
-	}
-	
-	// Convert players
-	if p1Data, ok := data["player1"].(map[string]interface{}); ok {
-		state.Player1 = ConvertToPlayer(p1Data)
+// ConvertToGameView converts decoded message data to a GameView
+func ConvertToGameView(data interface{}) (*battle.GameView, error) {
+	view := &battle.GameView{}
+	if err := convert(data, view); err != nil {
+		return nil, err
 	}
-	if p2Data, ok := data["player2"].(map[string]interface{}); ok {
-		state.Player2 = ConvertToPlayer(p2Data)
-	}
-	
-	return state
+	return view, nil
 }
 
-// ConvertToPlayer converts a map to Player
-func ConvertToPlayer(data map[string]interface{}) *battle.Player {
-	player := &battle.Player{
-		ID:      data["id"].(string),
-		HP:      int(data["hp"].(float64)),
-		Mana:    int(data["mana"].(float64)),
-		MaxMana: int(data["max_mana"].(float64)),
-	}
-	
-	// Convert cards arrays
-	if deckData, ok := data["deck"].([]interface{}); ok {
-		player.Deck = ConvertToCards(deckData)
-	}
-	if handData, ok := data["hand"].([]interface{}); ok {
-		player.Hand = ConvertToCards(handData)
-	}
-	if fieldData, ok := data["field"].([]interface{}); ok {
-		player.Field = ConvertToCards(fieldData)
-	}
-	if graveyardData, ok := data["graveyard"].([]interface{}); ok {
-		player.Graveyard = ConvertToCards(graveyardData)
-	}
-	
-	return player
+// ConvertToAction converts decoded message data to an Action
+func ConvertToAction(data interface{}) (battle.Action, error) {
+	var action battle.Action
+	err := convert(data, &action)
+	return action, err
+}
+
+// ConvertToActions converts decoded message data to a list of Actions
+func ConvertToActions(data interface{}) ([]battle.Action, error) {
+	var actions []battle.Action
+	err := convert(data, &actions)
+	return actions, err
+}
+
+// ConvertToCards converts decoded message data to a list of Cards
+func ConvertToCards(data interface{}) ([]battle.Card, error) {
+	var cards []battle.Card
+	err := convert(data, &cards)
+	return cards, err
 }
 
-// ConvertToCards converts an array of card data to Card slice
-func ConvertToCards(data []interface{}) []battle.Card {
-	cards := make([]battle.Card, len(data))
-	for i, cardData := range data {
-		if cardMap, ok := cardData.(map[string]interface{}); ok {
-			cards[i] = battle.Card{
-				ID:         cardMap["id"].(string),
-				Name:       cardMap["name"].(string),
-				Archetype:  battle.Archetype(cardMap["archetype"].(string)),
-				Attack:     int(cardMap["attack"].(float64)),
-				Defense:    int(cardMap["defense"].(float64)),
-				Cost:       int(cardMap["cost"].(float64)),
-				Effect:     cardMap["effect"].(string),
-				EffectType: cardMap["effect_type"].(string),
-			}
-		}
+// convert re-decodes generic JSON message data into a typed value
+func convert(data interface{}, target interface{}) error {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return err
 	}
-	return cards
-}
\ No newline at end of file
+	return json.Unmarshal(raw, target)
+}